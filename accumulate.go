@@ -0,0 +1,181 @@
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MessageAccumulator folds the events of a MessageResponse into a single
+// MessageStart. It handles both streaming and non-streaming responses so
+// callers can use the same code path for either.
+//
+// The zero value is ready to use.
+type MessageAccumulator struct {
+	msg         *MessageStart
+	done        bool
+	partialJSON map[int]*strings.Builder
+}
+
+// Accumulate reads every event from resp and returns the complete message.
+// Error events (ClaudeError, ClientError) are returned as errors.
+// resp is closed before Accumulate returns.
+func Accumulate(resp MessageResponse) (*MessageStart, error) {
+	defer resp.Close()
+
+	var acc MessageAccumulator
+	for evt := range resp.Responses() {
+		if err := acc.Add(evt); err != nil {
+			return nil, err
+		}
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+
+	return acc.Result()
+}
+
+// Add applies a single event to the message being built.
+func (a *MessageAccumulator) Add(evt MessageEvent) error {
+	switch ev := evt.Data.(type) {
+	case *MessageStart:
+		msg := *ev
+		msg.Content = append([]TurnContent(nil), ev.Content...)
+		a.msg = &msg
+		// non-streaming responses are delivered as a single complete message
+		if evt.Type != "message_start" {
+			a.done = true
+		}
+	case *MessagePing:
+	case *ContentBlockStart:
+		if a.msg == nil {
+			return errors.New("content_block_start received before message_start")
+		}
+		block := ev.Block
+		if block == nil {
+			return fmt.Errorf("unknown content block type: %s", ev.ContentBlock.Type)
+		}
+		// blocks start in order, so the index is either a new block
+		// at the end or one already present
+		switch {
+		case ev.Index < 0 || ev.Index > len(a.msg.Content):
+			return fmt.Errorf("content_block_start for out of range index %d", ev.Index)
+		case ev.Index == len(a.msg.Content):
+			a.msg.Content = append(a.msg.Content, block)
+		default:
+			a.msg.Content[ev.Index] = block
+		}
+	case *ContentBlockDelta:
+		block, err := a.block(int(ev.Index))
+		if err != nil {
+			return err
+		}
+		switch b := block.(type) {
+		case *turnContentText:
+			b.Text += ev.Delta.Text
+			if ev.Delta.Citation != nil {
+				b.Citations = append(b.Citations, ev.Delta.Citation)
+			}
+		case *TurnContentThinking:
+			b.Thinking += ev.Delta.Thinking
+			b.Signature += ev.Delta.Signature
+		case *TurnContentToolUse, *TurnContentServerToolUse:
+			if a.partialJSON == nil {
+				a.partialJSON = make(map[int]*strings.Builder)
+			}
+			sb := a.partialJSON[int(ev.Index)]
+			if sb == nil {
+				sb = &strings.Builder{}
+				a.partialJSON[int(ev.Index)] = sb
+			}
+			sb.WriteString(ev.Delta.PartialJson)
+		case *TurnContentUnknown:
+			// the block is kept as sent in content_block_start; deltas for
+			// block types this library doesn't know can't be applied.
+		default:
+			return fmt.Errorf("unexpected delta %q for content block type %s", ev.Delta.Type, block.Type())
+		}
+	case *ContentBlockStop:
+		block, err := a.block(int(ev.Index))
+		if err != nil {
+			return err
+		}
+		switch toolUse := block.(type) {
+		case *TurnContentToolUse:
+			input, err := a.toolInput(int(ev.Index))
+			if err != nil {
+				return err
+			}
+			toolUse.Input = input
+		case *TurnContentServerToolUse:
+			input, err := a.toolInput(int(ev.Index))
+			if err != nil {
+				return err
+			}
+			toolUse.Input = input
+		}
+	case *MessageDelta:
+		if a.msg == nil {
+			return errors.New("message_delta received before message_start")
+		}
+		a.msg.StopReason = ev.Delta.StopReason
+		a.msg.StopSequence = ev.Delta.StopSequence
+		// usage in message_delta is cumulative for the whole message
+		a.msg.Usage.OutputTokens = int(ev.Usage.OutputTokens)
+		if ev.Usage.InputTokens > 0 {
+			a.msg.Usage.InputTokens = int(ev.Usage.InputTokens)
+		}
+		if ev.Usage.CacheCreationInputTokens > 0 {
+			a.msg.Usage.CacheCreationInputTokens = int(ev.Usage.CacheCreationInputTokens)
+		}
+		if ev.Usage.CacheReadInputTokens > 0 {
+			a.msg.Usage.CacheReadInputTokens = int(ev.Usage.CacheReadInputTokens)
+		}
+		if ev.Usage.ServerToolUse != nil {
+			a.msg.Usage.ServerToolUse = ev.Usage.ServerToolUse
+		}
+	case *MessageStop:
+		a.done = true
+	case *ClaudeError:
+		return ev
+	case *ClientError:
+		return ev
+	default:
+		return fmt.Errorf("unexpected event type: %s %T", evt.Type, evt.Data)
+	}
+
+	return nil
+}
+
+// Result returns the accumulated message. It returns an error if the
+// response ended before the message was complete.
+func (a *MessageAccumulator) Result() (*MessageStart, error) {
+	if a.msg == nil {
+		return nil, errors.New("response contained no message")
+	}
+	if !a.done {
+		return a.msg, errors.New("response ended before message_stop")
+	}
+	return a.msg, nil
+}
+
+func (a *MessageAccumulator) block(idx int) (TurnContent, error) {
+	if a.msg == nil || idx < 0 || idx >= len(a.msg.Content) || a.msg.Content[idx] == nil {
+		return nil, fmt.Errorf("event for unknown content block index %d", idx)
+	}
+	return a.msg.Content[idx], nil
+}
+
+// toolInput parses the input json collected for the tool use block at idx.
+func (a *MessageAccumulator) toolInput(idx int) (any, error) {
+	var input any = map[string]any{}
+	if sb := a.partialJSON[idx]; sb != nil && sb.Len() > 0 {
+		if err := json.Unmarshal([]byte(sb.String()), &input); err != nil {
+			return nil, fmt.Errorf("parse tool_use input for block %d: %w", idx, err)
+		}
+	}
+	delete(a.partialJSON, idx)
+	return input, nil
+}
@@ -0,0 +1,402 @@
+package claude
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type staticResponse struct {
+	events []MessageEvent
+}
+
+func (r *staticResponse) Responses() <-chan MessageEvent {
+	ch := make(chan MessageEvent, len(r.events))
+	for _, evt := range r.events {
+		ch <- evt
+	}
+	close(ch)
+	return ch
+}
+
+func (r *staticResponse) Metadata() ResponseMetadata {
+	return ResponseMetadata{}
+}
+
+func (r *staticResponse) Close() error {
+	return nil
+}
+
+func (r *staticResponse) Err() error {
+	return nil
+}
+
+func TestAccumulateStreaming(t *testing.T) {
+	rawEvents := []struct {
+		name string
+		data string
+		msg  MessageContent
+	}{
+		{"message_start", `{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude-3-7-sonnet-20250219","stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":472,"output_tokens":2}}}`, &MessageStart{}},
+		{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`, &ContentBlockStart{}},
+		{"ping", `{"type":"ping"}`, &MessagePing{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Okay, let me check"}}`, &ContentBlockDelta{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" the weather."}}`, &ContentBlockDelta{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":0}`, &ContentBlockStop{}},
+		{"content_block_start", `{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_01","name":"get_weather","input":{}}}`, &ContentBlockStart{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":""}}`, &ContentBlockDelta{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"location\":"}}`, &ContentBlockDelta{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":" \"San Francisco, CA\"}"}}`, &ContentBlockDelta{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":1}`, &ContentBlockStop{}},
+		{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"tool_use","stop_sequence":null},"usage":{"output_tokens":89}}`, &MessageDelta{}},
+		{"message_stop", `{"type":"message_stop"}`, &MessageStop{}},
+	}
+
+	var resp staticResponse
+	for _, raw := range rawEvents {
+		if err := json.Unmarshal([]byte(raw.data), raw.msg); err != nil {
+			t.Fatal(err)
+		}
+		resp.events = append(resp.events, MessageEvent{Type: raw.name, Data: raw.msg})
+	}
+
+	got, err := Accumulate(&resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := MessageStart{
+		ID:         "msg_1",
+		Type:       "message",
+		Role:       "assistant",
+		Model:      "claude-3-7-sonnet-20250219",
+		StopReason: "tool_use",
+		Content: []TurnContent{
+			TextContent("Okay, let me check the weather."),
+			&TurnContentToolUse{
+				Typ:   TurnToolUse,
+				ID:    "toolu_01",
+				Name:  "get_weather",
+				Input: map[string]any{"location": "San Francisco, CA"},
+			},
+		},
+	}
+	expect.Usage.InputTokens = 472
+	expect.Usage.OutputTokens = 89
+
+	if diff := cmp.Diff(&expect, got); diff != "" {
+		t.Fatalf("Accumulate() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAccumulateThinking(t *testing.T) {
+	rawEvents := []struct {
+		name string
+		data string
+		msg  MessageContent
+	}{
+		{"message_start", `{"type":"message_start","message":{"id":"msg_2","type":"message","role":"assistant","content":[],"model":"claude-3-7-sonnet-20250219","stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":50,"output_tokens":1}}}`, &MessageStart{}},
+		{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`, &ContentBlockStart{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Let me solve this step by step:\n\n1. First break down 27 * 453"}}`, &ContentBlockDelta{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"\n2. 453 = 400 + 50 + 3"}}`, &ContentBlockDelta{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"EqQBCgIYAhIM1gbcDa9GJwZA2b3hGgxBdjrkzLoky3dl1pkiMOYds"}}`, &ContentBlockDelta{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":0}`, &ContentBlockStop{}},
+		{"content_block_start", `{"type":"content_block_start","index":1,"content_block":{"type":"redacted_thinking","data":"EmwKAhgBEgy3va3pzix"}}`, &ContentBlockStart{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":1}`, &ContentBlockStop{}},
+		{"content_block_start", `{"type":"content_block_start","index":2,"content_block":{"type":"text","text":""}}`, &ContentBlockStart{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":2,"delta":{"type":"text_delta","text":"27 * 453 = 12,231"}}`, &ContentBlockDelta{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":2}`, &ContentBlockStop{}},
+		{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":100}}`, &MessageDelta{}},
+		{"message_stop", `{"type":"message_stop"}`, &MessageStop{}},
+	}
+
+	var resp staticResponse
+	for _, raw := range rawEvents {
+		if err := json.Unmarshal([]byte(raw.data), raw.msg); err != nil {
+			t.Fatal(err)
+		}
+		resp.events = append(resp.events, MessageEvent{Type: raw.name, Data: raw.msg})
+	}
+
+	got, err := Accumulate(&resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectContent := []TurnContent{
+		&TurnContentThinking{
+			Typ:       TurnThinking,
+			Thinking:  "Let me solve this step by step:\n\n1. First break down 27 * 453\n2. 453 = 400 + 50 + 3",
+			Signature: "EqQBCgIYAhIM1gbcDa9GJwZA2b3hGgxBdjrkzLoky3dl1pkiMOYds",
+		},
+		&TurnContentRedactedThinking{
+			Typ:  TurnRedactedThinking,
+			Data: "EmwKAhgBEgy3va3pzix",
+		},
+		TextContent("27 * 453 = 12,231"),
+	}
+
+	if diff := cmp.Diff(expectContent, got.Content); diff != "" {
+		t.Fatalf("Accumulate() mismatch (-want +got):\n%s", diff)
+	}
+	if got.Text() != "27 * 453 = 12,231" {
+		t.Fatalf("Text() should exclude thinking, got %q", got.Text())
+	}
+}
+
+func TestAccumulateUnknownBlock(t *testing.T) {
+	rawEvents := []struct {
+		name string
+		data string
+		msg  MessageContent
+	}{
+		{"message_start", `{"type":"message_start","message":{"id":"msg_4","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-20250514","stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":20,"output_tokens":1}}}`, &MessageStart{}},
+		{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"future_block","payload":""}}`, &ContentBlockStart{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"future_delta","payload":"abc"}}`, &ContentBlockDelta{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":0}`, &ContentBlockStop{}},
+		{"content_block_start", `{"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`, &ContentBlockStart{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Done."}}`, &ContentBlockDelta{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":1}`, &ContentBlockStop{}},
+		{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":10}}`, &MessageDelta{}},
+		{"message_stop", `{"type":"message_stop"}`, &MessageStop{}},
+	}
+
+	var resp staticResponse
+	for _, raw := range rawEvents {
+		if err := json.Unmarshal([]byte(raw.data), raw.msg); err != nil {
+			t.Fatal(err)
+		}
+		resp.events = append(resp.events, MessageEvent{Type: raw.name, Data: raw.msg})
+	}
+
+	got, err := Accumulate(&resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectContent := []TurnContent{
+		&TurnContentUnknown{
+			Typ: "future_block",
+			Raw: json.RawMessage(`{"type":"future_block","payload":""}`),
+		},
+		TextContent("Done."),
+	}
+	if diff := cmp.Diff(expectContent, got.Content); diff != "" {
+		t.Fatalf("Accumulate() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAccumulateNonStreaming(t *testing.T) {
+	msg := &MessageStart{
+		ID:         "msg_1",
+		Type:       "message",
+		StopReason: "end_turn",
+		Content:    []TurnContent{TextContent("hi")},
+	}
+	resp := staticResponse{
+		events: []MessageEvent{{Type: "message", Data: msg}},
+	}
+
+	got, err := Accumulate(&resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(msg, got); diff != "" {
+		t.Fatalf("Accumulate() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAccumulateErrors(t *testing.T) {
+	apiErr := &ClaudeError{}
+	apiErr.Err.Type = "overloaded_error"
+
+	resp := staticResponse{
+		events: []MessageEvent{
+			{Type: "message_start", Data: &MessageStart{ID: "msg_1", Type: "message"}},
+			{Type: "error", Data: apiErr},
+		},
+	}
+	_, err := Accumulate(&resp)
+	if err != apiErr {
+		t.Fatalf("expected ClaudeError, got %v", err)
+	}
+
+	resp = staticResponse{
+		events: []MessageEvent{
+			{Type: "message_start", Data: &MessageStart{ID: "msg_1", Type: "message"}},
+		},
+	}
+	_, err = Accumulate(&resp)
+	if err == nil {
+		t.Fatal("expected error for truncated stream")
+	}
+
+	for _, idx := range []int{-1, 1, 1 << 30} {
+		resp = staticResponse{
+			events: []MessageEvent{
+				{Type: "message_start", Data: &MessageStart{ID: "msg_1", Type: "message"}},
+				{Type: "content_block_start", Data: &ContentBlockStart{Index: idx, Block: TextContent("")}},
+			},
+		}
+		_, err = Accumulate(&resp)
+		if err == nil {
+			t.Fatalf("expected error for content_block_start index %d", idx)
+		}
+	}
+}
+
+func TestAccumulateCitations(t *testing.T) {
+	rawEvents := []struct {
+		name string
+		data string
+		msg  MessageContent
+	}{
+		{"message_start", `{"type":"message_start","message":{"id":"msg_3","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-20250514","stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":610,"output_tokens":1}}}`, &MessageStart{}},
+		{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`, &ContentBlockStart{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"According to the document, "}}`, &ContentBlockDelta{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":0}`, &ContentBlockStop{}},
+		{"content_block_start", `{"type":"content_block_start","index":1,"content_block":{"type":"text","text":"","citations":[]}}`, &ContentBlockStart{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":1,"delta":{"type":"citations_delta","citation":{"type":"char_location","cited_text":"The grass is green. ","document_index":0,"document_title":"Example Document","start_char_index":0,"end_char_index":20}}}`, &ContentBlockDelta{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"the grass is green"}}`, &ContentBlockDelta{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":1}`, &ContentBlockStop{}},
+		{"content_block_start", `{"type":"content_block_start","index":2,"content_block":{"type":"text","text":" and "}}`, &ContentBlockStart{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":2}`, &ContentBlockStop{}},
+		{"content_block_start", `{"type":"content_block_start","index":3,"content_block":{"type":"text","text":"the sky is blue","citations":[{"type":"page_location","cited_text":"The sky is blue.","document_index":1,"start_page_number":2,"end_page_number":3}]}}`, &ContentBlockStart{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":3}`, &ContentBlockStop{}},
+		{"content_block_start", `{"type":"content_block_start","index":4,"content_block":{"type":"text","text":"."}}`, &ContentBlockStart{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":4}`, &ContentBlockStop{}},
+		{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":40}}`, &MessageDelta{}},
+		{"message_stop", `{"type":"message_stop"}`, &MessageStop{}},
+	}
+
+	var resp staticResponse
+	for _, raw := range rawEvents {
+		if err := json.Unmarshal([]byte(raw.data), raw.msg); err != nil {
+			t.Fatal(err)
+		}
+		resp.events = append(resp.events, MessageEvent{Type: raw.name, Data: raw.msg})
+	}
+
+	got, err := Accumulate(&resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectCitations := [][]Citation{
+		nil,
+		{
+			&CharLocationCitation{
+				CitedText:      "The grass is green. ",
+				DocumentTitle:  "Example Document",
+				StartCharIndex: 0,
+				EndCharIndex:   20,
+			},
+		},
+		nil,
+		{
+			&PageLocationCitation{
+				CitedText:       "The sky is blue.",
+				DocumentIndex:   1,
+				StartPageNumber: 2,
+				EndPageNumber:   3,
+			},
+		},
+		nil,
+	}
+	var gotCitations [][]Citation
+	for _, c := range got.Content {
+		gotCitations = append(gotCitations, TextCitations(c))
+	}
+	if diff := cmp.Diff(expectCitations, gotCitations); diff != "" {
+		t.Fatalf("citations mismatch (-want +got):\n%s", diff)
+	}
+
+	expectText := `According to the document, the grass is green[1] and the sky is blue[2].
+
+[1] "The grass is green." - Example Document
+[2] "The sky is blue." - Document 1, p. 2`
+	if diff := cmp.Diff(expectText, got.TextWithFootnotes()); diff != "" {
+		t.Fatalf("TextWithFootnotes() mismatch (-want +got):\n%s", diff)
+	}
+
+	// citations are sent back to the API as part of the assistant turn
+	b, err := json.Marshal(got.Content[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectJSON := `{"type":"text","text":"the sky is blue","citations":[{"type":"page_location","cited_text":"The sky is blue.","document_index":1,"start_page_number":2,"end_page_number":3}]}`
+	if diff := cmp.Diff(expectJSON, string(b)); diff != "" {
+		t.Fatalf("MarshalJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAccumulateUnknownCitation(t *testing.T) {
+	rawEvents := []struct {
+		name string
+		data string
+		msg  MessageContent
+	}{
+		{"message_start", `{"type":"message_start","message":{"id":"msg_5","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-20250514","stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":100,"output_tokens":1}}}`, &MessageStart{}},
+		{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":"","citations":[]}}`, &ContentBlockStart{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"citations_delta","citation":{"type":"future_location","cited_text":"The grass is green.","future_index":7}}}`, &ContentBlockDelta{}},
+		{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"the grass is green"}}`, &ContentBlockDelta{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":0}`, &ContentBlockStop{}},
+		{"content_block_start", `{"type":"content_block_start","index":1,"content_block":{"type":"text","text":" and blue","citations":[{"type":"future_location","cited_text":"It is blue.","future_index":8}]}}`, &ContentBlockStart{}},
+		{"content_block_stop", `{"type":"content_block_stop","index":1}`, &ContentBlockStop{}},
+		{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":20}}`, &MessageDelta{}},
+		{"message_stop", `{"type":"message_stop"}`, &MessageStop{}},
+	}
+
+	var resp staticResponse
+	for _, raw := range rawEvents {
+		if err := json.Unmarshal([]byte(raw.data), raw.msg); err != nil {
+			t.Fatal(err)
+		}
+		resp.events = append(resp.events, MessageEvent{Type: raw.name, Data: raw.msg})
+	}
+
+	got, err := Accumulate(&resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectCitations := [][]Citation{
+		{
+			&UnknownCitation{
+				Typ: "future_location",
+				Raw: json.RawMessage(`{"type":"future_location","cited_text":"The grass is green.","future_index":7}`),
+			},
+		},
+		{
+			&UnknownCitation{
+				Typ: "future_location",
+				Raw: json.RawMessage(`{"type":"future_location","cited_text":"It is blue.","future_index":8}`),
+			},
+		},
+	}
+	var gotCitations [][]Citation
+	for _, c := range got.Content {
+		gotCitations = append(gotCitations, TextCitations(c))
+	}
+	if diff := cmp.Diff(expectCitations, gotCitations); diff != "" {
+		t.Fatalf("citations mismatch (-want +got):\n%s", diff)
+	}
+
+	expectText := `the grass is green[1] and blue[2]
+
+[1] "The grass is green." - future_location
+[2] "It is blue." - future_location`
+	if diff := cmp.Diff(expectText, got.TextWithFootnotes()); diff != "" {
+		t.Fatalf("TextWithFootnotes() mismatch (-want +got):\n%s", diff)
+	}
+
+	b, err := json.Marshal(got.Content[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectJSON := `{"type":"text","text":" and blue","citations":[{"type":"future_location","cited_text":"It is blue.","future_index":8}]}`
+	if diff := cmp.Diff(expectJSON, string(b)); diff != "" {
+		t.Fatalf("MarshalJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
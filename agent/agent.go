@@ -0,0 +1,145 @@
+// Package agent implements a tool-use loop on top of clientiface.Client.
+//
+// A Runner sends a request to the model, executes any tool_use blocks
+// in the response using registered Go handlers, appends the results to
+// the conversation and calls the model again until it stops asking for tools.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+)
+
+var (
+	// ErrMaxIterations is returned when the model is still requesting tools
+	// after the configured number of iterations.
+	ErrMaxIterations = errors.New("agent: max iterations reached")
+	// ErrMaxTokens is returned when the conversation has consumed more
+	// than the configured token budget.
+	ErrMaxTokens = errors.New("agent: max tokens reached")
+)
+
+// ToolHandler executes a single tool_use request from the model.
+// The returned string is sent back to the model as the tool result.
+// If an error is returned its message is sent back to the model instead,
+// as a tool result with is_error set.
+type ToolHandler func(ctx context.Context, toolUse *claude.TurnContentToolUse) (string, error)
+
+type Runner struct {
+	client        clientiface.Client
+	tools         map[string]ToolHandler
+	maxIterations int
+	maxTokens     int
+}
+
+// NewRunner creates a Runner. tools maps each Tool.Name in the request
+// to the handler that implements it.
+func NewRunner(client clientiface.Client, tools map[string]ToolHandler, opts ...Option) *Runner {
+	r := &Runner{
+		client:        client,
+		tools:         tools,
+		maxIterations: 10,
+	}
+	for _, opt := range opts {
+		opt.set(r)
+	}
+	return r
+}
+
+// Result is the outcome of a Run.
+type Result struct {
+	// Messages is the full conversation transcript, including the
+	// messages from the original request.
+	Messages []claude.MessageTurn
+	// Final is the last message returned by the model.
+	Final *claude.MessageStart
+	// Iterations is the number of model calls made.
+	Iterations int
+	// InputTokens and OutputTokens are summed across all model calls.
+	InputTokens  int
+	OutputTokens int
+}
+
+// Run drives the conversation in req until the model stops with a
+// stop reason other than tool_use. req is not modified.
+//
+// If a limit is reached, Run returns the transcript so far along with
+// ErrMaxIterations or ErrMaxTokens.
+func (r *Runner) Run(ctx context.Context, req *claude.MessageRequest, options ...clientiface.Option) (*Result, error) {
+	result := &Result{
+		Messages: append([]claude.MessageTurn(nil), req.Messages...),
+	}
+
+	for {
+		if r.maxIterations > 0 && result.Iterations >= r.maxIterations {
+			return result, ErrMaxIterations
+		}
+
+		// clients may modify the request so send a fresh copy
+		// on every iteration.
+		iterReq := *req
+		iterReq.Messages = result.Messages
+
+		resp, err := r.client.Message(ctx, &iterReq, options...)
+		if err != nil {
+			return result, err
+		}
+		msg, err := claude.Accumulate(resp)
+		if err != nil {
+			return result, err
+		}
+
+		result.Iterations++
+		result.Final = msg
+		result.InputTokens += msg.Usage.InputTokens
+		result.OutputTokens += msg.Usage.OutputTokens
+		result.Messages = append(result.Messages, claude.MessageTurn{
+			Role:    claude.RoleAssistant,
+			Content: msg.Content,
+		})
+
+		if msg.StopReason != "tool_use" {
+			return result, nil
+		}
+
+		var toolResults []claude.TurnContent
+		for _, content := range msg.Content {
+			toolUse, ok := content.(*claude.TurnContentToolUse)
+			if !ok {
+				continue
+			}
+			toolResults = append(toolResults, r.runTool(ctx, toolUse))
+		}
+
+		if len(toolResults) == 0 {
+			return result, errors.New("agent: stop_reason tool_use but no tool_use blocks in response")
+		}
+
+		result.Messages = append(result.Messages, claude.MessageTurn{
+			Role:    claude.RoleUser,
+			Content: toolResults,
+		})
+
+		if r.maxTokens > 0 && result.InputTokens+result.OutputTokens >= r.maxTokens {
+			return result, ErrMaxTokens
+		}
+	}
+}
+
+func (r *Runner) runTool(ctx context.Context, toolUse *claude.TurnContentToolUse) claude.TurnContent {
+	handler, ok := r.tools[toolUse.Name]
+	if !ok {
+		return claude.ToolErrorContent(toolUse.ID, fmt.Sprintf("Error: unknown tool %q", toolUse.Name))
+	}
+
+	out, err := handler(ctx, toolUse)
+	if err != nil {
+		return claude.ToolErrorContent(toolUse.ID, fmt.Sprintf("Error: %s", err))
+	}
+
+	return claude.ToolResultContent(toolUse.ID, out)
+}
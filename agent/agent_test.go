@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+)
+
+type fakeResponse struct {
+	msg *claude.MessageStart
+}
+
+func (r *fakeResponse) Responses() <-chan claude.MessageEvent {
+	ch := make(chan claude.MessageEvent, 1)
+	ch <- claude.MessageEvent{Type: "message", Data: r.msg}
+	close(ch)
+	return ch
+}
+
+func (r *fakeResponse) Metadata() claude.ResponseMetadata {
+	return claude.ResponseMetadata{}
+}
+
+func (r *fakeResponse) Close() error {
+	return nil
+}
+
+func (r *fakeResponse) Err() error {
+	return nil
+}
+
+type fakeClient struct {
+	responses []*claude.MessageStart
+	requests  []claude.MessageRequest
+}
+
+func (c *fakeClient) Message(ctx context.Context, req *claude.MessageRequest, options ...clientiface.Option) (claude.MessageResponse, error) {
+	c.requests = append(c.requests, *req)
+	req.Model = "" // simulate a client that mutates the request
+	if len(c.responses) == 0 {
+		return nil, errors.New("no more responses")
+	}
+	msg := c.responses[0]
+	c.responses = c.responses[1:]
+	return &fakeResponse{msg: msg}, nil
+}
+
+func toolUseMessage(id, name string, input any) *claude.MessageStart {
+	msg := &claude.MessageStart{
+		Type:       "message",
+		Role:       claude.RoleAssistant,
+		StopReason: "tool_use",
+		Content: []claude.TurnContent{
+			&claude.TurnContentToolUse{Typ: claude.TurnToolUse, ID: id, Name: name, Input: input},
+		},
+	}
+	msg.Usage.InputTokens = 100
+	msg.Usage.OutputTokens = 10
+	return msg
+}
+
+func TestRun(t *testing.T) {
+	final := &claude.MessageStart{
+		Type:       "message",
+		Role:       claude.RoleAssistant,
+		StopReason: "end_turn",
+		Content:    []claude.TurnContent{claude.TextContent("It is 6.")},
+	}
+	client := &fakeClient{
+		responses: []*claude.MessageStart{
+			toolUseMessage("toolu_1", "add", map[string]any{"a": 1.0, "b": 5.0}),
+			final,
+		},
+	}
+
+	tools := map[string]ToolHandler{
+		"add": func(ctx context.Context, toolUse *claude.TurnContentToolUse) (string, error) {
+			return "6", nil
+		},
+	}
+
+	req := &claude.MessageRequest{
+		Model: claude.Claude3Haiku,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("what is 1+5?")}},
+		},
+	}
+
+	result, err := NewRunner(client, tools).Run(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Final.Text() != "It is 6." {
+		t.Fatalf("unexpected final message: %+v", result.Final)
+	}
+	if result.Iterations != 2 {
+		t.Fatalf("expected 2 iterations, got %d", result.Iterations)
+	}
+	if len(result.Messages) != 4 {
+		t.Fatalf("expected 4 messages in transcript, got %d", len(result.Messages))
+	}
+	if len(req.Messages) != 1 {
+		t.Fatalf("original request was modified")
+	}
+
+	toolResult := result.Messages[2]
+	if toolResult.Role != claude.RoleUser || toolResult.Content[0].TextContent() != "6" {
+		t.Fatalf("unexpected tool result turn: %+v", toolResult)
+	}
+
+	for i, r := range client.requests {
+		if r.Model != claude.Claude3Haiku {
+			t.Fatalf("request %d: model not preserved: %q", i, r.Model)
+		}
+	}
+	if len(client.requests[1].Messages) != 3 {
+		t.Fatalf("expected second request to carry 3 messages, got %d", len(client.requests[1].Messages))
+	}
+}
+
+func TestRunMaxIterations(t *testing.T) {
+	client := &fakeClient{
+		responses: []*claude.MessageStart{
+			toolUseMessage("toolu_1", "loop", nil),
+			toolUseMessage("toolu_2", "loop", nil),
+		},
+	}
+	tools := map[string]ToolHandler{
+		"loop": func(ctx context.Context, toolUse *claude.TurnContentToolUse) (string, error) {
+			return "", errors.New("try again")
+		},
+	}
+
+	req := &claude.MessageRequest{
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("go")}},
+		},
+	}
+
+	result, err := NewRunner(client, tools, WithMaxIterations(2)).Run(context.Background(), req)
+	if err != ErrMaxIterations {
+		t.Fatalf("expected ErrMaxIterations, got %v", err)
+	}
+	if result.Iterations != 2 {
+		t.Fatalf("expected 2 iterations, got %d", result.Iterations)
+	}
+	if got := result.Messages[2].Content[0].TextContent(); got != "Error: try again" {
+		t.Fatalf("unexpected tool error result: %q", got)
+	}
+	if !claude.IsToolError(result.Messages[2].Content[0]) {
+		t.Fatal("expected tool error result to set is_error")
+	}
+
+	client = &fakeClient{
+		responses: []*claude.MessageStart{
+			toolUseMessage("toolu_1", "loop", nil),
+		},
+	}
+	_, err = NewRunner(client, tools, WithMaxTokens(50)).Run(context.Background(), req)
+	if err != ErrMaxTokens {
+		t.Fatalf("expected ErrMaxTokens, got %v", err)
+	}
+}
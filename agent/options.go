@@ -0,0 +1,38 @@
+package agent
+
+type Option interface {
+	set(*Runner)
+}
+
+type maxIterationsOption struct {
+	n int
+}
+
+func (o *maxIterationsOption) set(r *Runner) {
+	r.maxIterations = o.n
+}
+
+// WithMaxIterations limits the number of model calls made by a single Run.
+// A value of 0 means no limit. The default is 10.
+func WithMaxIterations(n int) Option {
+	return &maxIterationsOption{
+		n: n,
+	}
+}
+
+type maxTokensOption struct {
+	n int
+}
+
+func (o *maxTokensOption) set(r *Runner) {
+	r.maxTokens = o.n
+}
+
+// WithMaxTokens limits the total number of input and output tokens
+// consumed across all model calls made by a single Run.
+// A value of 0 (the default) means no limit.
+func WithMaxTokens(n int) Option {
+	return &maxTokensOption{
+		n: n,
+	}
+}
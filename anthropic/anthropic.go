@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/psanford/claude"
 	"github.com/psanford/claude/clientiface"
 	"github.com/psanford/claude/internal/request"
 	"github.com/psanford/claude/internal/responseparser"
+	"github.com/psanford/claude/internal/retry"
 )
 
 var MessagesURL = "https://api.anthropic.com/v1/messages"
@@ -19,6 +22,7 @@ type Client struct {
 	apiKey       string
 	roundTripper http.RoundTripper
 	debugLogger  *slog.Logger
+	retryPolicy  *clientiface.RetryPolicy
 }
 
 var clientIfaceAssert = clientiface.Client(&Client{})
@@ -34,40 +38,135 @@ func NewClient(apiKey string, opts ...Option) *Client {
 }
 
 func (c *Client) Message(ctx context.Context, req *claude.MessageRequest, options ...clientiface.Option) (claude.MessageResponse, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	// work on a copy so the caller can reuse req
+	reqCopy := *req
+	req = &reqCopy
 	request.SetDefaults(req)
+
+	// anthropic_beta is only valid in the request body for bedrock
+	betas := append(req.RequiredBetas(), req.AnthropicBeta...)
+	req.AnthropicBeta = nil
+
 	jsonReq, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", MessagesURL, bytes.NewReader(jsonReq))
-	if err != nil {
-		return nil, err
+	betas = append([]string{"max-tokens-3-5-sonnet-2024-07-15", "output-128k-2025-02-19"}, betas...)
+	headers := c.headers(ro, betas...)
+
+	// the timeout is applied by retry.Do so it covers every attempt
+	client := c.httpClient(0)
+
+	debugLogger := c.debugLogger
+	if ro.DebugLogger != nil {
+		debugLogger = ro.DebugLogger
+	}
+
+	retryPolicy := c.retryPolicy
+	if ro.RetryPolicy != nil {
+		retryPolicy = ro.RetryPolicy
+	}
+
+	return retry.Do(ctx, ro.Timeout, retryPolicy, responseparser.ClassifyError, func(ctx context.Context) (claude.MessageResponse, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", MessagesURL, bytes.NewReader(jsonReq))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header = headers.Clone()
+
+		start := time.Now()
+		resp, err := client.Do(httpReq)
+		if err != nil {
+			return nil, err
+		}
+		elapsed := time.Since(start)
+
+		if ro.ResponseHook != nil {
+			ro.ResponseHook(resp)
+		}
+
+		return responseparser.HandleResponse(ctx, resp, elapsed, debugLogger)
+	})
+}
+
+func (c *Client) httpClient(timeout time.Duration) *http.Client {
+	if c.roundTripper == nil && timeout == 0 {
+		return http.DefaultClient
+	}
+	return &http.Client{
+		Transport: c.roundTripper,
+		Timeout:   timeout,
 	}
+}
 
+// headers returns the headers sent with every API request.
+func (c *Client) headers(ro *clientiface.RequestOptions, betas ...string) http.Header {
 	headers := make(http.Header)
 	headers.Add("anthropic-version", "2023-06-01")
-	headers.Add("anthropic-beta", "max-tokens-3-5-sonnet-2024-07-15")
-	headers.Add("anthropic-beta", "output-128k-2025-02-19")
 	headers.Add("x-api-key", c.apiKey)
 	headers.Add("content-type", "application/json")
-	httpReq.Header = headers
+	for _, beta := range betas {
+		headers.Add("anthropic-beta", beta)
+	}
+	request.ApplyHeaders(headers, ro)
+	return headers
+}
 
-	client := c.httpClient()
+// do sends a request to one of the non-messages API endpoints.
+// Non-2xx responses are returned as a *claude.APIError.
+func (c *Client) do(ctx context.Context, method, url string, body io.Reader, ro *clientiface.RequestOptions, betas ...string) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header = c.headers(ro, betas...)
 
-	resp, err := client.Do(httpReq)
+	resp, err := c.httpClient(ro.Timeout).Do(httpReq)
 	if err != nil {
 		return nil, err
 	}
 
-	return responseparser.HandleResponse(ctx, resp, c.debugLogger)
+	if ro.ResponseHook != nil {
+		ro.ResponseHook(resp)
+	}
+
+	debugLogger := c.debugLogger
+	if ro.DebugLogger != nil {
+		debugLogger = ro.DebugLogger
+	}
+	if debugLogger != nil && debugLogger.Enabled(ctx, slog.LevelDebug) {
+		debugLogger.Debug("response", "method", method, "url", url, "status", resp.StatusCode)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, responseparser.NewAPIError(resp)
+	}
+
+	return resp, nil
 }
 
-func (c *Client) httpClient() *http.Client {
-	if c.roundTripper == nil {
-		return http.DefaultClient
+// doJSON sends body (if not nil) as json and decodes the json response into out (if not nil).
+func (c *Client) doJSON(ctx context.Context, method, url string, body, out any, ro *clientiface.RequestOptions, betas ...string) error {
+	var bodyReader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		bodyReader = bytes.NewReader(jsonBody)
 	}
-	return &http.Client{
-		Transport: c.roundTripper,
+
+	resp, err := c.do(ctx, method, url, bodyReader, ro, betas...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
 	}
+	return json.NewDecoder(resp.Body).Decode(out)
 }
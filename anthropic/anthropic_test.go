@@ -0,0 +1,596 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+)
+
+// redirectTransport sends every request to a local test server.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient("test-key", WithRoundTripper(&redirectTransport{target: u}))
+}
+
+const helloResponse = `{
+  "id": "msg_01",
+  "type": "message",
+  "role": "assistant",
+  "model": "claude-3-haiku-20240307",
+  "content": [{"type": "text", "text": "Hello!"}],
+  "stop_reason": "end_turn",
+  "stop_sequence": null,
+  "usage": {"input_tokens": 10, "output_tokens": 3}
+}`
+
+func TestMessageRequestOptions(t *testing.T) {
+	var gotHeader http.Header
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		gotHeader = r.Header.Clone()
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "application/json")
+		w.Header().Set("request-id", "req_123")
+		io.WriteString(w, helloResponse)
+	}))
+
+	var hookStatus int
+	var hookRequestID string
+	req := &claude.MessageRequest{
+		Model: claude.Claude3Haiku,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	resp, err := client.Message(context.Background(), req,
+		clientiface.WithHeader("x-custom", "custom-value"),
+		clientiface.WithBeta("some-beta-2025-01-01"),
+		clientiface.WithRequestID("my-request"),
+		clientiface.WithTimeout(10*time.Second),
+		clientiface.WithResponseHook(func(r *http.Response) {
+			hookStatus = r.StatusCode
+			hookRequestID = r.Header.Get("request-id")
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := claude.Accumulate(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Text() != "Hello!" {
+		t.Fatalf("unexpected response text: %q", msg.Text())
+	}
+
+	md := resp.Metadata()
+	if md.RequestID != "req_123" || md.StatusCode != 200 {
+		t.Errorf("unexpected response metadata: %+v", md)
+	}
+
+	if got := gotHeader.Get("x-custom"); got != "custom-value" {
+		t.Errorf("x-custom header = %q", got)
+	}
+	if got := gotHeader.Get(clientiface.RequestIDHeader); got != "my-request" {
+		t.Errorf("request id header = %q", got)
+	}
+	betas := gotHeader.Values("anthropic-beta")
+	if len(betas) == 0 || betas[len(betas)-1] != "some-beta-2025-01-01" {
+		t.Errorf("anthropic-beta headers = %v", betas)
+	}
+	if hookStatus != 200 || hookRequestID != "req_123" {
+		t.Errorf("response hook got status=%d request-id=%q", hookStatus, hookRequestID)
+	}
+}
+
+func TestMessageRetry(t *testing.T) {
+	var calls int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		calls++
+		switch calls {
+		case 1:
+			w.Header().Set("content-type", "application/json")
+			w.Header().Set("retry-after", "0")
+			w.WriteHeader(529)
+			io.WriteString(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+		case 2:
+			w.Header().Set("content-type", "text/event-stream")
+			io.WriteString(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+		default:
+			w.Header().Set("content-type", "application/json")
+			io.WriteString(w, helloResponse)
+		}
+	}))
+
+	policy := clientiface.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	client.retryPolicy = policy
+
+	req := &claude.MessageRequest{
+		Model: claude.Claude3Haiku,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	resp, err := client.Message(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := claude.Accumulate(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Text() != "Hello!" || calls != 3 {
+		t.Fatalf("got text=%q after %d calls", msg.Text(), calls)
+	}
+
+	calls = 0
+	_, err = client.Message(context.Background(), req, clientiface.WithRetryPolicy(&clientiface.RetryPolicy{MaxAttempts: 1}))
+	if err == nil || calls != 1 {
+		t.Fatalf("expected error without retries, got err=%v after %d calls", err, calls)
+	}
+}
+
+func TestMessageRetryTransportAndRetryAfter(t *testing.T) {
+	var calls int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		calls++
+		switch calls {
+		case 1:
+			// drop the connection before sending headers
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+		case 2:
+			w.Header().Set("content-type", "application/json")
+			w.Header().Set("retry-after", "3600")
+			w.WriteHeader(529)
+			io.WriteString(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+		default:
+			w.Header().Set("content-type", "application/json")
+			io.WriteString(w, helloResponse)
+		}
+	}))
+
+	policy := clientiface.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 10 * time.Millisecond
+
+	req := &claude.MessageRequest{
+		Model: claude.Claude3Haiku,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+
+	start := time.Now()
+	resp, err := client.Message(context.Background(), req, clientiface.WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := claude.Accumulate(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Text() != "Hello!" || calls != 3 {
+		t.Fatalf("got text=%q after %d calls", msg.Text(), calls)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("retry-after was not capped at MaxBackoff, took %s", elapsed)
+	}
+}
+
+func TestMessageRetryAfterPastDate(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "application/json")
+		w.Header().Set("retry-after", "Wed, 21 Oct 2015 07:28:00 GMT")
+		w.WriteHeader(429)
+		io.WriteString(w, `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`)
+	}))
+
+	req := &claude.MessageRequest{
+		Model: claude.Claude3Haiku,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	_, err := client.Message(context.Background(), req)
+
+	var apiErr *claude.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %T %v", err, err)
+	}
+	if apiErr.RetryAfter != 0 {
+		t.Fatalf("expected RetryAfter 0 for a past date, got %s", apiErr.RetryAfter)
+	}
+}
+
+func TestMessageTimeoutWithRetries(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		calls.Add(1)
+		select {
+		case <-time.After(150 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Header().Set("retry-after", "0")
+		w.WriteHeader(529)
+		io.WriteString(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	}))
+
+	policy := clientiface.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+
+	req := &claude.MessageRequest{
+		Model: claude.Claude3Haiku,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+
+	// each attempt finishes within the timeout, but all of them together don't
+	_, err := client.Message(context.Background(), req, clientiface.WithRetryPolicy(policy), clientiface.WithTimeout(200*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected the timeout to end the second attempt, got %d calls", n)
+	}
+}
+
+func TestMessageAPIError(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "application/json")
+		w.Header().Set("request-id", "req_456")
+		w.Header().Set("retry-after", "30")
+		w.Header().Set("anthropic-ratelimit-requests-limit", "50")
+		w.Header().Set("anthropic-ratelimit-requests-remaining", "0")
+		w.Header().Set("anthropic-ratelimit-requests-reset", "2025-01-01T00:00:30Z")
+		w.WriteHeader(429)
+		io.WriteString(w, `{"type":"error","error":{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}}`)
+	}))
+
+	req := &claude.MessageRequest{
+		Model: claude.Claude3Haiku,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	_, err := client.Message(context.Background(), req)
+
+	var apiErr *claude.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %T %v", err, err)
+	}
+	if !claude.IsRateLimit(err) || claude.IsOverloaded(err) {
+		t.Fatalf("unexpected error classification: %v", err)
+	}
+	if apiErr.StatusCode != 429 || apiErr.RequestID != "req_456" || apiErr.RetryAfter != 30*time.Second {
+		t.Fatalf("unexpected APIError fields: %+v", apiErr)
+	}
+	expectReset := time.Date(2025, 1, 1, 0, 0, 30, 0, time.UTC)
+	rl := apiErr.RateLimit
+	if rl.RequestsLimit == nil || *rl.RequestsLimit != 50 || rl.RequestsRemaining == nil || *rl.RequestsRemaining != 0 || rl.RequestsReset == nil || !rl.RequestsReset.Equal(expectReset) {
+		t.Fatalf("unexpected rate limit: %+v", rl)
+	}
+	if rl.TokensLimit != nil || rl.TokensRemaining != nil || rl.TokensReset != nil {
+		t.Fatalf("expected missing token rate limit headers to be nil: %+v", rl)
+	}
+}
+
+func TestMessageToolBetas(t *testing.T) {
+	var gotHeader http.Header
+	var gotBody map[string]any
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, helloResponse)
+	}))
+
+	req := &claude.MessageRequest{
+		Model: claude.Claude3Dot7SonnetLatest,
+		Tools: []claude.Tool{
+			claude.ComputerTool(claude.ToolTypeComputer20250124, 1024, 768),
+			claude.BashTool(claude.ToolTypeBash20250124),
+			claude.TextEditorTool(claude.ToolTypeTextEditor20250124),
+		},
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("take a screenshot")}},
+		},
+	}
+	resp, err := client.Message(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := claude.Accumulate(resp); err != nil {
+		t.Fatal(err)
+	}
+
+	var computerUseBetas int
+	for _, beta := range gotHeader.Values("anthropic-beta") {
+		if beta == "computer-use-2025-01-24" {
+			computerUseBetas++
+		}
+	}
+	if computerUseBetas != 1 {
+		t.Errorf("expected computer-use beta once, got headers %v", gotHeader.Values("anthropic-beta"))
+	}
+
+	tools := gotBody["tools"].([]any)
+	computer := tools[0].(map[string]any)
+	if computer["type"] != "computer_20250124" || computer["name"] != "computer" || computer["display_width_px"] != float64(1024) {
+		t.Errorf("unexpected computer tool: %v", computer)
+	}
+	if _, ok := computer["input_schema"]; ok {
+		t.Errorf("input_schema should not be sent for anthropic-defined tools")
+	}
+}
+
+func TestMessageStreamClose(t *testing.T) {
+	testMessageStreamClose(t)
+}
+
+func TestMessageStreamCloseRetry(t *testing.T) {
+	testMessageStreamClose(t, clientiface.WithRetryPolicy(clientiface.DefaultRetryPolicy()))
+}
+
+func testMessageStreamClose(t *testing.T, options ...clientiface.Option) {
+	handlerDone := make(chan struct{})
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(handlerDone)
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "text/event-stream")
+		io.WriteString(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"model\":\"claude-3-haiku-20240307\",\"usage\":{\"input_tokens\":10,\"output_tokens\":1}}}\n\n")
+		w.(http.Flusher).Flush()
+
+		// keep sending until the client goes away so events are
+		// in flight when the response is closed
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(time.Millisecond):
+			}
+			io.WriteString(w, "event: ping\ndata: {\"type\":\"ping\"}\n\n")
+			w.(http.Flusher).Flush()
+		}
+	}))
+
+	req := &claude.MessageRequest{
+		Model:  claude.Claude3Haiku,
+		Stream: true,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	resp, err := client.Message(context.Background(), req, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evt := <-resp.Responses()
+	if evt.Type != "message_start" {
+		t.Fatalf("unexpected first event: %+v", evt)
+	}
+	if err := resp.Err(); err != nil {
+		t.Fatalf("unexpected err before close: %v", err)
+	}
+
+	// let some pings queue up
+	time.Sleep(20 * time.Millisecond)
+
+	resp.Close()
+
+	if err := resp.Err(); !errors.Is(err, claude.ErrResponseClosed) {
+		t.Fatalf("expected ErrResponseClosed, got %v", err)
+	}
+	select {
+	case evt, ok := <-resp.Responses():
+		if ok {
+			t.Fatalf("unexpected event after close: %+v", evt)
+		}
+	default:
+		t.Fatal("Responses channel not closed after Close returned")
+	}
+
+	select {
+	case <-handlerDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not released after Close")
+	}
+}
+
+func TestMessageStreamErr(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "text/event-stream")
+		io.WriteString(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"model\":\"claude-3-haiku-20240307\",\"usage\":{\"input_tokens\":10,\"output_tokens\":1}}}\n\n")
+		io.WriteString(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	}))
+
+	req := &claude.MessageRequest{
+		Model:  claude.Claude3Haiku,
+		Stream: true,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	resp, err := client.Message(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	for range resp.Responses() {
+	}
+
+	if !claude.IsOverloaded(resp.Err()) {
+		t.Fatalf("expected overloaded error, got %v", resp.Err())
+	}
+}
+
+func TestMessageReuseRequest(t *testing.T) {
+	var gotBetas [][]string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBetas = append(gotBetas, r.Header.Values("anthropic-beta"))
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, helloResponse)
+	}))
+
+	req := &claude.MessageRequest{
+		Model:         claude.Claude3Haiku,
+		AnthropicBeta: []string{"files-api-2025-04-14"},
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+
+	for i, opts := range [][]clientiface.Option{
+		{clientiface.WithBeta("some-beta-2025-01-01")},
+		nil,
+	} {
+		resp, err := client.Message(context.Background(), req, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := claude.Accumulate(resp); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Contains(gotBetas[i], "files-api-2025-04-14") {
+			t.Errorf("call %d: anthropic-beta headers = %v, missing request beta", i, gotBetas[i])
+		}
+		if hasOpt := slices.Contains(gotBetas[i], "some-beta-2025-01-01"); hasOpt != (len(opts) > 0) {
+			t.Errorf("call %d: anthropic-beta headers = %v", i, gotBetas[i])
+		}
+	}
+
+	if !slices.Equal(req.AnthropicBeta, []string{"files-api-2025-04-14"}) {
+		t.Errorf("request AnthropicBeta modified: %v", req.AnthropicBeta)
+	}
+}
+
+func TestMessageStreamCancelRetry(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "text/event-stream")
+		io.WriteString(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"model\":\"claude-3-haiku-20240307\",\"usage\":{\"input_tokens\":10,\"output_tokens\":1}}}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req := &claude.MessageRequest{
+		Model:  claude.Claude3Haiku,
+		Stream: true,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	resp, err := client.Message(ctx, req, clientiface.WithRetryPolicy(clientiface.DefaultRetryPolicy()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	if evt := <-resp.Responses(); evt.Type != "message_start" {
+		t.Fatalf("unexpected first event: %+v", evt)
+	}
+
+	cancel()
+
+	var last claude.MessageEvent
+	for evt := range resp.Responses() {
+		last = evt
+	}
+	clientErr, ok := last.Data.(*claude.ClientError)
+	if !ok || !errors.Is(clientErr, context.Canceled) {
+		t.Fatalf("expected context.Canceled terminal event, got %+v", last)
+	}
+}
+
+func TestMessageStreamErrorAfterBufferedEvent(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "text/event-stream")
+		io.WriteString(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_01\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"model\":\"claude-3-haiku-20240307\",\"usage\":{\"input_tokens\":10,\"output_tokens\":1}}}\n\n")
+		io.WriteString(w, "event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\",\"stop_sequence\":null},\"usage\":{\"output_tokens\":5}}\n\n")
+		io.WriteString(w, "malformed\n\n")
+	}))
+
+	req := &claude.MessageRequest{
+		Model:  claude.Claude3Haiku,
+		Stream: true,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	resp, err := client.Message(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	if evt := <-resp.Responses(); evt.Type != "message_start" {
+		t.Fatalf("unexpected first event: %+v", evt)
+	}
+
+	// give the stream time to buffer message_delta and hit the error
+	time.Sleep(50 * time.Millisecond)
+
+	var types []string
+	for evt := range resp.Responses() {
+		types = append(types, evt.Type)
+	}
+	if !slices.Equal(types, []string{"message_delta", "_client_error"}) {
+		t.Errorf("got events %v, want [message_delta _client_error]", types)
+	}
+	if resp.Err() == nil {
+		t.Errorf("expected Err to be set")
+	}
+}
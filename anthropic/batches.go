@@ -0,0 +1,267 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+	"github.com/psanford/claude/internal/request"
+)
+
+var BatchesURL = "https://api.anthropic.com/v1/messages/batches"
+
+var (
+	// ErrBatchRequestCanceled is the error for batch results that were
+	// canceled before being processed.
+	ErrBatchRequestCanceled = errors.New("batch request canceled")
+	// ErrBatchRequestExpired is the error for batch results that were not
+	// processed before the batch expired.
+	ErrBatchRequestExpired = errors.New("batch request expired")
+)
+
+// BatchRequest is a single message request in a batch.
+type BatchRequest struct {
+	// CustomID identifies the request in the batch results. It must be unique within the batch.
+	CustomID string `json:"custom_id"`
+	// Params is the message request. Streaming is not supported for batches.
+	Params *claude.MessageRequest `json:"params"`
+}
+
+// Batch is a Message Batch.
+// See https://docs.anthropic.com/en/api/creating-message-batches for details.
+type Batch struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// ProcessingStatus is one of "in_progress", "canceling" or "ended".
+	ProcessingStatus string `json:"processing_status"`
+	RequestCounts    struct {
+		Processing int `json:"processing"`
+		Succeeded  int `json:"succeeded"`
+		Errored    int `json:"errored"`
+		Canceled   int `json:"canceled"`
+		Expired    int `json:"expired"`
+	} `json:"request_counts"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	EndedAt           *time.Time `json:"ended_at"`
+	ArchivedAt        *time.Time `json:"archived_at"`
+	CancelInitiatedAt *time.Time `json:"cancel_initiated_at"`
+	// ResultsURL is set once processing has ended.
+	ResultsURL string `json:"results_url"`
+}
+
+// Ended reports whether the batch has finished processing.
+func (b *Batch) Ended() bool {
+	return b.ProcessingStatus == "ended"
+}
+
+// ListParams controls pagination for list endpoints.
+type ListParams struct {
+	// Limit is the number of items per page. The API default is 20.
+	Limit int
+	// BeforeID returns the page of results immediately before this ID.
+	BeforeID string
+	// AfterID returns the page of results immediately after this ID.
+	AfterID string
+}
+
+func (p *ListParams) encode(baseURL string) string {
+	if p == nil {
+		return baseURL
+	}
+	q := make(url.Values)
+	if p.Limit > 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.BeforeID != "" {
+		q.Set("before_id", p.BeforeID)
+	}
+	if p.AfterID != "" {
+		q.Set("after_id", p.AfterID)
+	}
+	if len(q) == 0 {
+		return baseURL
+	}
+	return baseURL + "?" + q.Encode()
+}
+
+// BatchList is a page of batches.
+type BatchList struct {
+	Data    []Batch `json:"data"`
+	HasMore bool    `json:"has_more"`
+	FirstID string  `json:"first_id"`
+	LastID  string  `json:"last_id"`
+}
+
+// CreateBatch submits a batch of message requests for asynchronous processing.
+// The requests are not modified. The beta flags of all requests, including
+// those from MessageRequest.RequiredBetas, are sent with the batch.
+func (c *Client) CreateBatch(ctx context.Context, requests []BatchRequest, options ...clientiface.Option) (*Batch, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	body := struct {
+		Requests []BatchRequest `json:"requests"`
+	}{
+		Requests: make([]BatchRequest, len(requests)),
+	}
+
+	// anthropic_beta is only valid in the request body for bedrock, so
+	// the betas for all requests are sent as headers on the batch
+	var betas []string
+	seen := make(map[string]bool)
+	for i, r := range requests {
+		if r.Params == nil {
+			return nil, fmt.Errorf("batch request %q has no params", r.CustomID)
+		}
+		for _, beta := range append(r.Params.RequiredBetas(), r.Params.AnthropicBeta...) {
+			if !seen[beta] {
+				seen[beta] = true
+				betas = append(betas, beta)
+			}
+		}
+
+		params := *r.Params
+		request.SetDefaults(&params)
+		params.Stream = false
+		params.AnthropicBeta = nil
+		body.Requests[i] = BatchRequest{
+			CustomID: r.CustomID,
+			Params:   &params,
+		}
+	}
+
+	var batch Batch
+	err := c.doJSON(ctx, "POST", BatchesURL, body, &batch, ro, betas...)
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// GetBatch fetches the current state of a batch.
+func (c *Client) GetBatch(ctx context.Context, batchID string, options ...clientiface.Option) (*Batch, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	var batch Batch
+	err := c.doJSON(ctx, "GET", BatchesURL+"/"+url.PathEscape(batchID), nil, &batch, ro)
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// ListBatches lists batches, most recently created first. params may be nil.
+func (c *Client) ListBatches(ctx context.Context, params *ListParams, options ...clientiface.Option) (*BatchList, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	var list BatchList
+	err := c.doJSON(ctx, "GET", params.encode(BatchesURL), nil, &list, ro)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// CancelBatch starts canceling a batch. Requests that have already
+// been processed are not canceled.
+func (c *Client) CancelBatch(ctx context.Context, batchID string, options ...clientiface.Option) (*Batch, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	var batch Batch
+	err := c.doJSON(ctx, "POST", BatchesURL+"/"+url.PathEscape(batchID)+"/cancel", nil, &batch, ro)
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// BatchResult is the result of a single request in a batch.
+type BatchResult struct {
+	CustomID string
+	// Type is one of "succeeded", "errored", "canceled" or "expired".
+	Type string
+	// Message is set for succeeded results.
+	Message *claude.MessageStart
+	// Err is set for all other results. Errored results are returned as a
+	// *claude.APIError; canceled and expired results as ErrBatchRequestCanceled
+	// and ErrBatchRequestExpired.
+	Err error
+}
+
+// BatchResults streams the results of an ended batch. Results are not
+// guaranteed to be in the same order as the requests; use CustomID to
+// match them up. The caller must Close the returned reader.
+func (c *Client) BatchResults(ctx context.Context, batchID string, options ...clientiface.Option) (*BatchResultReader, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	resp, err := c.do(ctx, "GET", BatchesURL+"/"+url.PathEscape(batchID)+"/results", nil, ro)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchResultReader{
+		body: resp.Body,
+		dec:  json.NewDecoder(resp.Body),
+	}, nil
+}
+
+// BatchResultReader reads the JSONL results of a batch one entry at a time.
+type BatchResultReader struct {
+	body io.ReadCloser
+	dec  *json.Decoder
+}
+
+// Next returns the next result. It returns io.EOF when there are no more results.
+func (r *BatchResultReader) Next() (*BatchResult, error) {
+	var line struct {
+		CustomID string `json:"custom_id"`
+		Result   struct {
+			Type    string               `json:"type"`
+			Message *claude.MessageStart `json:"message"`
+			Error   struct {
+				Error struct {
+					Type    string `json:"type"`
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"error"`
+		} `json:"result"`
+	}
+
+	if err := r.dec.Decode(&line); err != nil {
+		return nil, err
+	}
+
+	result := &BatchResult{
+		CustomID: line.CustomID,
+		Type:     line.Result.Type,
+	}
+
+	switch line.Result.Type {
+	case "succeeded":
+		result.Message = line.Result.Message
+	case "errored":
+		result.Err = &claude.APIError{
+			Type:    line.Result.Error.Error.Type,
+			Message: line.Result.Error.Error.Message,
+		}
+	case "canceled":
+		result.Err = ErrBatchRequestCanceled
+	case "expired":
+		result.Err = ErrBatchRequestExpired
+	default:
+		result.Err = fmt.Errorf("unknown batch result type: %s", line.Result.Type)
+	}
+
+	return result, nil
+}
+
+func (r *BatchResultReader) Close() error {
+	return r.body.Close()
+}
@@ -0,0 +1,164 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/psanford/claude"
+)
+
+const batchJSON = `{
+  "id": "msgbatch_01",
+  "type": "message_batch",
+  "processing_status": "ended",
+  "request_counts": {"processing": 0, "succeeded": 1, "errored": 1, "canceled": 1, "expired": 0},
+  "ended_at": "2024-08-20T18:37:24.100435Z",
+  "created_at": "2024-08-20T18:37:24.100435Z",
+  "expires_at": "2024-08-21T18:37:24.100435Z",
+  "archived_at": null,
+  "cancel_initiated_at": null,
+  "results_url": "https://api.anthropic.com/v1/messages/batches/msgbatch_01/results"
+}`
+
+const batchResultsJSONL = `{"custom_id":"req-1","result":{"type":"succeeded","message":{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Hello!"}],"stop_reason":"end_turn","stop_sequence":null,"usage":{"input_tokens":10,"output_tokens":3}}}}
+{"custom_id":"req-2","result":{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: Field required"}}}}
+{"custom_id":"req-3","result":{"type":"canceled"}}
+`
+
+func TestBatches(t *testing.T) {
+	var createBetas []string
+	var createBody struct {
+		Requests []struct {
+			CustomID string         `json:"custom_id"`
+			Params   map[string]any `json:"params"`
+		} `json:"requests"`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/messages/batches", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		switch r.Method {
+		case "POST":
+			createBetas = r.Header.Values("anthropic-beta")
+			if err := json.NewDecoder(r.Body).Decode(&createBody); err != nil {
+				t.Error(err)
+			}
+			io.WriteString(w, batchJSON)
+		case "GET":
+			if got := r.URL.Query().Get("limit"); got != "5" {
+				t.Errorf("limit = %q", got)
+			}
+			io.WriteString(w, `{"data":[`+batchJSON+`],"has_more":false,"first_id":"msgbatch_01","last_id":"msgbatch_01"}`)
+		}
+	})
+	mux.HandleFunc("/v1/messages/batches/msgbatch_01", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, batchJSON)
+	})
+	mux.HandleFunc("/v1/messages/batches/msgbatch_01/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("cancel method = %s", r.Method)
+		}
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, batchJSON)
+	})
+	mux.HandleFunc("/v1/messages/batches/msgbatch_01/results", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/binary")
+		io.WriteString(w, batchResultsJSONL)
+	})
+
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	req := &claude.MessageRequest{
+		Model:  claude.Claude3Haiku,
+		Stream: true,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	computerReq := &claude.MessageRequest{
+		Model:         claude.Claude3Dot7SonnetLatest,
+		AnthropicBeta: []string{"token-efficient-tools-2025-02-19"},
+		Tools:         []claude.Tool{claude.ComputerTool(claude.ToolTypeComputer20250124, 1024, 768)},
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.ImageFileContent("file_01")}},
+		},
+	}
+	batch, err := client.CreateBatch(ctx, []BatchRequest{
+		{CustomID: "req-1", Params: req},
+		{CustomID: "req-2", Params: computerReq},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.ID != "msgbatch_01" || !batch.Ended() || batch.RequestCounts.Succeeded != 1 {
+		t.Fatalf("unexpected batch: %+v", batch)
+	}
+	if len(createBody.Requests) != 2 || createBody.Requests[0].CustomID != "req-1" {
+		t.Fatalf("unexpected create body: %+v", createBody)
+	}
+	for _, beta := range []string{"computer-use-2025-01-24", claude.FilesBeta, "token-efficient-tools-2025-02-19"} {
+		if !slices.Contains(createBetas, beta) {
+			t.Errorf("anthropic-beta headers %v missing %s", createBetas, beta)
+		}
+	}
+	if _, ok := createBody.Requests[1].Params["anthropic_beta"]; ok {
+		t.Errorf("anthropic_beta should not be sent in batch params")
+	}
+	if _, ok := createBody.Requests[0].Params["stream"]; ok {
+		t.Errorf("stream should not be sent for batch requests")
+	}
+	if !req.Stream || req.MaxTokens != 0 {
+		t.Errorf("CreateBatch modified the caller's request")
+	}
+
+	if _, err := client.GetBatch(ctx, "msgbatch_01"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CancelBatch(ctx, "msgbatch_01"); err != nil {
+		t.Fatal(err)
+	}
+	list, err := client.ListBatches(ctx, &ListParams{Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 1 || list.LastID != "msgbatch_01" {
+		t.Fatalf("unexpected batch list: %+v", list)
+	}
+
+	results, err := client.BatchResults(ctx, "msgbatch_01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer results.Close()
+
+	var got []*BatchResult
+	for {
+		result, err := results.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, result)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(got))
+	}
+	if got[0].CustomID != "req-1" || got[0].Message == nil || got[0].Message.Text() != "Hello!" {
+		t.Errorf("unexpected succeeded result: %+v", got[0])
+	}
+	if !claude.IsInvalidRequest(got[1].Err) {
+		t.Errorf("unexpected errored result: %+v", got[1])
+	}
+	if !errors.Is(got[2].Err, ErrBatchRequestCanceled) {
+		t.Errorf("unexpected canceled result: %+v", got[2])
+	}
+}
@@ -0,0 +1,64 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+)
+
+var CountTokensURL = "https://api.anthropic.com/v1/messages/count_tokens"
+
+var tokenCounterAssert = clientiface.TokenCounter(&Client{})
+
+// CountTokens returns the number of input tokens req would use, without
+// creating a message. Only the model, system prompt, messages, tools and
+// tool choice are considered; sampling parameters in req are ignored.
+func (c *Client) CountTokens(ctx context.Context, req *claude.MessageRequest, options ...clientiface.Option) (*claude.TokenCount, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	body, err := countTokensBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	betas := append(req.RequiredBetas(), req.AnthropicBeta...)
+
+	var count claude.TokenCount
+	err = c.doJSON(ctx, "POST", CountTokensURL, body, &count, ro, betas...)
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
+// countTokensBody converts a MessageRequest into the count_tokens request body
+// by dropping the fields that endpoint doesn't accept.
+func countTokensBody(req *claude.MessageRequest) (map[string]json.RawMessage, error) {
+	jsonReq, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(jsonReq, &body); err != nil {
+		return nil, err
+	}
+
+	for _, field := range []string{
+		"max_tokens",
+		"metadata",
+		"stop_sequences",
+		"stream",
+		"temperature",
+		"top_p",
+		"top_k",
+		"anthropic_version",
+		"anthropic_beta",
+	} {
+		delete(body, field)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,61 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+)
+
+func TestCountTokens(t *testing.T) {
+	var body map[string]any
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages/count_tokens" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, `{"input_tokens": 2095}`)
+	}))
+
+	req := &claude.MessageRequest{
+		Model:     claude.Claude3Dot7SonnetLatest,
+		System:    "You are a scientist",
+		MaxTokens: 1024,
+		Stream:    true,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("Hello, Claude")}},
+		},
+	}
+
+	var counter clientiface.Client = client
+	tc, ok := counter.(clientiface.TokenCounter)
+	if !ok {
+		t.Fatal("anthropic client should implement TokenCounter")
+	}
+
+	count, err := tc.CountTokens(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count.InputTokens != 2095 {
+		t.Fatalf("got %d input tokens", count.InputTokens)
+	}
+
+	for _, field := range []string{"model", "system", "messages"} {
+		if _, ok := body[field]; !ok {
+			t.Errorf("expected %s in request body", field)
+		}
+	}
+	for _, field := range []string{"max_tokens", "stream"} {
+		if _, ok := body[field]; ok {
+			t.Errorf("unexpected %s in request body", field)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+)
+
+var FilesURL = "https://api.anthropic.com/v1/files"
+
+// FilesBeta is the beta flag required by the Files API. It is sent
+// automatically by the file methods on Client, and by Message and
+// CountTokens when the request references an uploaded file.
+const FilesBeta = claude.FilesBeta
+
+// File is the metadata for an uploaded file.
+// See https://docs.anthropic.com/en/docs/build-with-claude/files for details.
+type File struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Filename  string    `json:"filename"`
+	MimeType  string    `json:"mime_type"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+	// Downloadable is true for files created by the code execution tool.
+	// Uploaded files cannot be downloaded.
+	Downloadable bool `json:"downloadable"`
+}
+
+// ContentBlock returns a content block that references the file: an image
+// block for image files and a document block for everything else.
+func (f *File) ContentBlock() claude.TurnContent {
+	if strings.HasPrefix(f.MimeType, "image/") {
+		return claude.ImageFileContent(f.ID)
+	}
+	return claude.FileDocumentContent(f.ID)
+}
+
+// FileList is a page of files.
+type FileList struct {
+	Data    []File `json:"data"`
+	HasMore bool   `json:"has_more"`
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+}
+
+// UploadFile uploads the contents of r as a new file.
+func (c *Client) UploadFile(ctx context.Context, filename, mimeType string, r io.Reader, options ...clientiface.Option) (*File, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		partHeader := make(textproto.MIMEHeader)
+		partHeader.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+			"name":     "file",
+			"filename": filename,
+		}))
+		partHeader.Set("Content-Type", mimeType)
+
+		part, err := mw.CreatePart(partHeader)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(part, r); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(mw.Close())
+	}()
+
+	// replace the default json content-type
+	uploadOpts := *ro
+	uploadOpts.Header = ro.Header.Clone()
+	if uploadOpts.Header == nil {
+		uploadOpts.Header = make(http.Header)
+	}
+	uploadOpts.Header.Set("content-type", mw.FormDataContentType())
+
+	resp, err := c.do(ctx, "POST", FilesURL, pr, &uploadOpts, FilesBeta)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var f File
+	if err := json.NewDecoder(resp.Body).Decode(&f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// ListFiles lists uploaded files, most recently created first. params may be nil.
+func (c *Client) ListFiles(ctx context.Context, params *ListParams, options ...clientiface.Option) (*FileList, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	var list FileList
+	err := c.doJSON(ctx, "GET", params.encode(FilesURL), nil, &list, ro, FilesBeta)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetFile fetches the metadata for a file.
+func (c *Client) GetFile(ctx context.Context, fileID string, options ...clientiface.Option) (*File, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	var f File
+	err := c.doJSON(ctx, "GET", FilesURL+"/"+url.PathEscape(fileID), nil, &f, ro, FilesBeta)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// DownloadFile returns the contents of a file. Only files created by
+// tools can be downloaded. The caller must Close the returned reader.
+func (c *Client) DownloadFile(ctx context.Context, fileID string, options ...clientiface.Option) (io.ReadCloser, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	resp, err := c.do(ctx, "GET", FilesURL+"/"+url.PathEscape(fileID)+"/content", nil, ro, FilesBeta)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// DeleteFile deletes a file.
+func (c *Client) DeleteFile(ctx context.Context, fileID string, options ...clientiface.Option) error {
+	ro := clientiface.NewRequestOptions(options...)
+
+	return c.doJSON(ctx, "DELETE", FilesURL+"/"+url.PathEscape(fileID), nil, nil, ro, FilesBeta)
+}
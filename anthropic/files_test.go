@@ -0,0 +1,156 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/psanford/claude"
+)
+
+const fileJSON = `{
+  "id": "file_011CNha8iCJcU1wXNR6q4V8w",
+  "type": "file",
+  "filename": "report.pdf",
+  "mime_type": "application/pdf",
+  "size_bytes": 8,
+  "created_at": "2025-04-14T18:37:24.100435Z",
+  "downloadable": false
+}`
+
+func TestFiles(t *testing.T) {
+	var uploaded, uploadedName, uploadedType string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("anthropic-beta"); got != FilesBeta {
+			t.Errorf("anthropic-beta = %q", got)
+		}
+		w.Header().Set("content-type", "application/json")
+		switch r.Method {
+		case "POST":
+			f, fh, err := r.FormFile("file")
+			if err != nil {
+				t.Error(err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			b, _ := io.ReadAll(f)
+			uploaded = string(b)
+			uploadedName = fh.Filename
+			uploadedType = fh.Header.Get("Content-Type")
+			io.WriteString(w, fileJSON)
+		case "GET":
+			if got := r.URL.Query().Get("after_id"); got != "file_0" {
+				t.Errorf("after_id = %q", got)
+			}
+			io.WriteString(w, `{"data":[`+fileJSON+`],"has_more":false,"first_id":"file_011CNha8iCJcU1wXNR6q4V8w","last_id":"file_011CNha8iCJcU1wXNR6q4V8w"}`)
+		}
+	})
+	mux.HandleFunc("/v1/files/file_011CNha8iCJcU1wXNR6q4V8w", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		switch r.Method {
+		case "GET":
+			io.WriteString(w, fileJSON)
+		case "DELETE":
+			io.WriteString(w, `{"id":"file_011CNha8iCJcU1wXNR6q4V8w","type":"file_deleted"}`)
+		}
+	})
+	mux.HandleFunc("/v1/files/file_011CNha8iCJcU1wXNR6q4V8w/content", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/pdf")
+		io.WriteString(w, "%PDF-1.4")
+	})
+	mux.HandleFunc("/v1/files/file_missing", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"type":"error","error":{"type":"not_found_error","message":"File not found"}}`)
+	})
+
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	f, err := client.UploadFile(ctx, "report.pdf", "application/pdf", strings.NewReader("%PDF-1.4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploaded != "%PDF-1.4" || uploadedName != "report.pdf" || uploadedType != "application/pdf" {
+		t.Fatalf("unexpected upload: name=%q type=%q body=%q", uploadedName, uploadedType, uploaded)
+	}
+	if f.ID != "file_011CNha8iCJcU1wXNR6q4V8w" || f.SizeBytes != 8 {
+		t.Fatalf("unexpected file: %+v", f)
+	}
+	if f.ContentBlock().Type() != claude.TurnDocument {
+		t.Errorf("expected document content block for pdf, got %s", f.ContentBlock().Type())
+	}
+
+	list, err := client.ListFiles(ctx, &ListParams{AfterID: "file_0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 1 || list.Data[0].Filename != "report.pdf" {
+		t.Fatalf("unexpected file list: %+v", list)
+	}
+
+	if _, err := client.GetFile(ctx, f.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := client.DownloadFile(ctx, f.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "%PDF-1.4" {
+		t.Errorf("downloaded %q", content)
+	}
+
+	if err := client.DeleteFile(ctx, f.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetFile(ctx, "file_missing")
+	if claude.ErrorType(err) != claude.ErrorTypeNotFound {
+		t.Errorf("expected not_found error, got %v", err)
+	}
+}
+
+func TestMessageFilesBeta(t *testing.T) {
+	var gotBetas []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBetas = r.Header.Values("anthropic-beta")
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, helloResponse)
+	}))
+
+	file := File{ID: "file_01", MimeType: "application/pdf"}
+	req := &claude.MessageRequest{
+		Model: claude.Claude3Haiku,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{file.ContentBlock(), claude.TextContent("summarize this")}},
+		},
+	}
+	resp, err := client.Message(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := claude.Accumulate(resp); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	for _, beta := range gotBetas {
+		if beta == FilesBeta {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("expected %s once, got anthropic-beta headers %v", FilesBeta, gotBetas)
+	}
+}
@@ -0,0 +1,52 @@
+package anthropic
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/psanford/claude/clientiface"
+)
+
+var ModelsURL = "https://api.anthropic.com/v1/models"
+
+// Model is a model available to the API key.
+// Use claude.LookupModel for capability information about a model.
+type Model struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ModelList is a page of models.
+type ModelList struct {
+	Data    []Model `json:"data"`
+	HasMore bool    `json:"has_more"`
+	FirstID string  `json:"first_id"`
+	LastID  string  `json:"last_id"`
+}
+
+// ListModels lists the available models, most recently released first. params may be nil.
+func (c *Client) ListModels(ctx context.Context, params *ListParams, options ...clientiface.Option) (*ModelList, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	var list ModelList
+	err := c.doJSON(ctx, "GET", params.encode(ModelsURL), nil, &list, ro)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetModel fetches a model by ID or alias. Aliases are resolved to the model they point to.
+func (c *Client) GetModel(ctx context.Context, modelID string, options ...clientiface.Option) (*Model, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	var m Model
+	err := c.doJSON(ctx, "GET", ModelsURL+"/"+url.PathEscape(modelID), nil, &m, ro)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -0,0 +1,62 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/psanford/claude"
+)
+
+func TestModels(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		switch r.URL.Query().Get("after_id") {
+		case "":
+			if got := r.URL.Query().Get("limit"); got != "1" {
+				t.Errorf("limit = %q", got)
+			}
+			io.WriteString(w, `{"data":[{"type":"model","id":"claude-sonnet-4-5-20250929","display_name":"Claude Sonnet 4.5","created_at":"2025-09-29T00:00:00Z"}],"has_more":true,"first_id":"claude-sonnet-4-5-20250929","last_id":"claude-sonnet-4-5-20250929"}`)
+		case "claude-sonnet-4-5-20250929":
+			io.WriteString(w, `{"data":[{"type":"model","id":"claude-opus-4-1-20250805","display_name":"Claude Opus 4.1","created_at":"2025-08-05T00:00:00Z"}],"has_more":false,"first_id":"claude-opus-4-1-20250805","last_id":"claude-opus-4-1-20250805"}`)
+		default:
+			t.Errorf("unexpected after_id: %q", r.URL.Query().Get("after_id"))
+		}
+	})
+	mux.HandleFunc("/v1/models/claude-sonnet-4-5", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, `{"type":"model","id":"claude-sonnet-4-5-20250929","display_name":"Claude Sonnet 4.5","created_at":"2025-09-29T00:00:00Z"}`)
+	})
+
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	var ids []string
+	params := &ListParams{Limit: 1}
+	for {
+		list, err := client.ListModels(ctx, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range list.Data {
+			ids = append(ids, m.ID)
+		}
+		if !list.HasMore {
+			break
+		}
+		params.AfterID = list.LastID
+	}
+	if len(ids) != 2 || ids[0] != claude.ClaudeSonnet4Dot5 || ids[1] != claude.ClaudeOpus4Dot1 {
+		t.Fatalf("unexpected models: %v", ids)
+	}
+
+	m, err := client.GetModel(ctx, claude.ClaudeSonnet4Dot5Latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != claude.ClaudeSonnet4Dot5 || m.DisplayName != "Claude Sonnet 4.5" || m.CreatedAt.Year() != 2025 {
+		t.Fatalf("unexpected model: %+v", m)
+	}
+}
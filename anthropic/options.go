@@ -3,6 +3,8 @@ package anthropic
 import (
 	"log/slog"
 	"net/http"
+
+	"github.com/psanford/claude/clientiface"
 )
 
 type Option interface {
@@ -38,3 +40,19 @@ func WithDebugLogger(l *slog.Logger) Option {
 		l: l,
 	}
 }
+
+type retryPolicyOption struct {
+	p *clientiface.RetryPolicy
+}
+
+func (o *retryPolicyOption) set(c *Client) {
+	c.retryPolicy = o.p
+}
+
+// WithRetryPolicy enables automatic retries of rate limit and overloaded
+// errors. See clientiface.DefaultRetryPolicy for a reasonable default.
+func WithRetryPolicy(p *clientiface.RetryPolicy) Option {
+	return &retryPolicyOption{
+		p: p,
+	}
+}
@@ -0,0 +1,109 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/psanford/claude"
+)
+
+func TestMessageWebSearch(t *testing.T) {
+	stream, err := os.ReadFile("testdata/web_search_stream.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var bodies []map[string]json.RawMessage
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		bodies = append(bodies, body)
+		w.Header().Set("content-type", "text/event-stream")
+		w.Write(stream)
+	}))
+
+	webSearch := claude.WebSearchTool()
+	webSearch.MaxUses = 3
+	webSearch.AllowedDomains = []string{"weather.example.com"}
+	webSearch.UserLocation = &claude.UserLocation{Type: "approximate", City: "New York", Country: "US"}
+
+	req := &claude.MessageRequest{
+		Model:  claude.Claude3Dot7SonnetLatest,
+		Stream: true,
+		Tools:  []claude.Tool{webSearch},
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("What's the weather in NYC?")}},
+		},
+	}
+	resp, err := client.Message(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := claude.Accumulate(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectTool := `[{"type":"web_search_20250305","name":"web_search","max_uses":3,"allowed_domains":["weather.example.com"],"user_location":{"type":"approximate","city":"New York","country":"US"}}]`
+	if diff := cmp.Diff(expectTool, string(bodies[0]["tools"])); diff != "" {
+		t.Fatalf("tools mismatch (-want +got):\n%s", diff)
+	}
+
+	expectContent := []claude.TurnContent{
+		claude.TextContent("I'll check the weather in NYC."),
+		&claude.TurnContentServerToolUse{
+			Typ:   claude.TurnServerToolUse,
+			ID:    "srvtoolu_014",
+			Name:  "web_search",
+			Input: map[string]any{"query": "weather NYC today"},
+		},
+		&claude.TurnContentWebSearchToolResult{
+			Typ:       claude.TurnWebSearchToolResult,
+			ToolUseID: "srvtoolu_014",
+			Results: []claude.WebSearchResult{
+				{
+					URL:              "https://weather.example.com/nyc",
+					Title:            "Weather in New York City",
+					EncryptedContent: "Ev0DCioIAxgCIiQ3",
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectContent, msg.Content[:3]); diff != "" {
+		t.Fatalf("content mismatch (-want +got):\n%s", diff)
+	}
+	if msg.Usage.ServerToolUse == nil || msg.Usage.ServerToolUse.WebSearchRequests != 1 {
+		t.Fatalf("unexpected server tool usage: %+v", msg.Usage.ServerToolUse)
+	}
+	citations := claude.TextCitations(msg.Content[3])
+	if len(citations) != 1 || citations[0].Type() != claude.CitationWebSearchResult {
+		t.Fatalf("unexpected citations: %+v", citations)
+	}
+
+	// the server tool blocks are sent back unchanged in the next turn
+	req.Messages = append(req.Messages,
+		claude.MessageTurn{Role: claude.RoleAssistant, Content: msg.Content},
+		claude.MessageTurn{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("And tomorrow?")}},
+	)
+	resp, err = client.Message(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := claude.Accumulate(resp); err != nil {
+		t.Fatal(err)
+	}
+
+	var history []claude.MessageTurn
+	if err := json.Unmarshal(bodies[1]["messages"], &history); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(msg.Content, history[1].Content); diff != "" {
+		t.Fatalf("history mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,120 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Error types returned by the API.
+// See https://docs.anthropic.com/en/api/errors for details.
+const (
+	ErrorTypeInvalidRequest  = "invalid_request_error"
+	ErrorTypeAuthentication  = "authentication_error"
+	ErrorTypePermission      = "permission_error"
+	ErrorTypeNotFound        = "not_found_error"
+	ErrorTypeRequestTooLarge = "request_too_large"
+	ErrorTypeRateLimit       = "rate_limit_error"
+	ErrorTypeAPI             = "api_error"
+	ErrorTypeOverloaded      = "overloaded_error"
+)
+
+// APIError is returned when the API responds with a non-success status.
+// All clients return this type, with provider specific errors mapped to
+// the equivalent Anthropic error type.
+type APIError struct {
+	// StatusCode is the http status code of the response.
+	StatusCode int
+	// Type is the API error type, e.g. ErrorTypeRateLimit.
+	Type string
+	// Message is the human readable error message.
+	Message string
+	// RequestID is the provider's identifier for the request, if available.
+	RequestID string
+	// RetryAfter is the delay requested by the server before retrying.
+	RetryAfter time.Duration
+	// RateLimit holds the anthropic-ratelimit-* response headers.
+	RateLimit RateLimit
+	// Cause is the underlying provider error, if any.
+	Cause error
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%d %s: %s", e.StatusCode, e.Type, e.Message)
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request-id: %s)", e.RequestID)
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Cause
+}
+
+func (e *APIError) IsRateLimit() bool {
+	return e.Type == ErrorTypeRateLimit
+}
+
+func (e *APIError) IsOverloaded() bool {
+	return e.Type == ErrorTypeOverloaded
+}
+
+func (e *APIError) IsInvalidRequest() bool {
+	return e.Type == ErrorTypeInvalidRequest
+}
+
+// ErrorTypeForStatus maps an http status code to the equivalent API error type.
+// It is used when a response doesn't include an error type.
+func ErrorTypeForStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return ErrorTypeInvalidRequest
+	case http.StatusUnauthorized:
+		return ErrorTypeAuthentication
+	case http.StatusForbidden:
+		return ErrorTypePermission
+	case http.StatusNotFound:
+		return ErrorTypeNotFound
+	case http.StatusRequestEntityTooLarge:
+		return ErrorTypeRequestTooLarge
+	case http.StatusTooManyRequests:
+		return ErrorTypeRateLimit
+	case http.StatusServiceUnavailable, 529:
+		return ErrorTypeOverloaded
+	}
+	if statusCode >= 500 {
+		return ErrorTypeAPI
+	}
+	return ""
+}
+
+// ErrorType returns the API error type of err. It understands both
+// APIError and ClaudeError (errors received in a response stream).
+// It returns an empty string for other errors.
+func ErrorType(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Type
+	}
+	var claudeErr *ClaudeError
+	if errors.As(err, &claudeErr) {
+		return claudeErr.Err.Type
+	}
+	return ""
+}
+
+// IsRateLimit reports whether err is a rate limit error.
+func IsRateLimit(err error) bool {
+	return ErrorType(err) == ErrorTypeRateLimit
+}
+
+// IsOverloaded reports whether err is an overloaded error.
+func IsOverloaded(err error) bool {
+	return ErrorType(err) == ErrorTypeOverloaded
+}
+
+// IsInvalidRequest reports whether err is an invalid request error.
+func IsInvalidRequest(err error) bool {
+	return ErrorType(err) == ErrorTypeInvalidRequest
+}
@@ -6,18 +6,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/psanford/claude"
 	"github.com/psanford/claude/clientiface"
 	"github.com/psanford/claude/internal/request"
+	"github.com/psanford/claude/internal/responseparser"
+	"github.com/psanford/claude/internal/retry"
 )
 
 type Client struct {
 	br          *bedrockruntime.Client
 	debugLogger *slog.Logger
+	retryPolicy *clientiface.RetryPolicy
+	geography   Geography
 }
 
 var clientIfaceAssert = clientiface.Client(&Client{})
@@ -33,6 +43,11 @@ func NewClient(bedrockClient *bedrockruntime.Client, opts ...Option) *Client {
 }
 
 func (c *Client) Message(ctx context.Context, req *claude.MessageRequest, options ...clientiface.Option) (claude.MessageResponse, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	// work on a copy so the caller can reuse req
+	reqCopy := *req
+	req = &reqCopy
 	request.SetDefaults(req)
 
 	if req.AnthropicVersion == "" {
@@ -43,95 +58,195 @@ func (c *Client) Message(ctx context.Context, req *claude.MessageRequest, option
 	if err != nil {
 		return nil, err
 	}
+	if c.geography != "" {
+		bedrockModel, err = bedrockModel.InferenceProfile(c.geography)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	req.Model = "" // bedrock doesn't support this field here
 
 	streaming := req.Stream
 	req.Stream = false // bedrock doesn't support this field here
 
+	// bedrock takes beta flags in the request body instead of a header
+	betas := append([]string(nil), req.AnthropicBeta...)
+	betas = append(betas, req.ToolBetas()...)
+	betas = append(betas, ro.Betas...)
+	req.AnthropicBeta = betas
+
 	jsonReq, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	if streaming {
-		output, err := c.br.InvokeModelWithResponseStream(context.Background(), &bedrockruntime.InvokeModelWithResponseStreamInput{
-			Body:        jsonReq,
-			ModelId:     aws.String(string(bedrockModel)),
-			ContentType: aws.String("application/json"),
-		})
+	debugLogger := c.debugLogger
+	if ro.DebugLogger != nil {
+		debugLogger = ro.DebugLogger
+	}
 
-		if err != nil {
-			return nil, err
+	retryPolicy := c.retryPolicy
+	if ro.RetryPolicy != nil {
+		retryPolicy = ro.RetryPolicy
+	}
+
+	return retry.Do(ctx, ro.Timeout, retryPolicy, responseparser.ClassifyError, func(ctx context.Context) (claude.MessageResponse, error) {
+		if streaming {
+			return c.invokeModelWithResponseStream(ctx, bedrockModel, jsonReq, ro, debugLogger)
 		}
+		return c.invokeModel(ctx, bedrockModel, jsonReq, ro, debugLogger)
+	})
+}
 
-		return handleStreaming(ctx, output)
-	} else {
-		out, err := c.br.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
-			Body:        jsonReq,
-			ModelId:     aws.String(string(bedrockModel)),
-			ContentType: aws.String("application/json"),
-			Accept:      aws.String("application/json"),
-		})
+func (c *Client) invokeModel(ctx context.Context, bedrockModel BedrockModel, jsonReq []byte, ro *clientiface.RequestOptions, debugLogger *slog.Logger) (claude.MessageResponse, error) {
+	start := time.Now()
+	out, err := c.br.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+		Body:        jsonReq,
+		ModelId:     aws.String(string(bedrockModel)),
+		ContentType: aws.String("application/json"),
+		Accept:      aws.String("application/json"),
+	}, requestOptFns(ro)...)
 
-		if err != nil {
-			return nil, err
-		}
+	if err != nil {
+		return nil, toAPIError(err)
+	}
 
-		var resp claude.MessageStart
-		err = json.Unmarshal(out.Body, &resp)
-		if err != nil {
-			return nil, err
-		}
+	metadata := responseMetadata(ro, out.ResultMetadata, time.Since(start))
 
-		ch := make(chan claude.MessageEvent)
-		meta := messageResponse{
-			responses: ch,
-		}
+	var resp claude.MessageStart
+	err = json.Unmarshal(out.Body, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if debugLogger != nil && debugLogger.Enabled(ctx, slog.LevelDebug) {
+		debugLogger.Debug("response", "message_start", resp)
+	}
 
-		evt := claude.MessageEvent{
-			Type: resp.Type,
-			Data: &resp,
-		}
-		go func() {
-			select {
-			case ch <- evt:
-			case <-ctx.Done():
-			}
+	evt := claude.MessageEvent{
+		Type: resp.Type,
+		Data: &resp,
+	}
+
+	s := responseparser.NewStream(metadata, nil)
+	go func() {
+		defer s.Finish()
+		s.Send(ctx, evt)
+	}()
+
+	return s, nil
+}
 
-			close(ch)
-		}()
+func (c *Client) invokeModelWithResponseStream(ctx context.Context, bedrockModel BedrockModel, jsonReq []byte, ro *clientiface.RequestOptions, debugLogger *slog.Logger) (claude.MessageResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
 
-		return &meta, nil
+	start := time.Now()
+	output, err := c.br.InvokeModelWithResponseStream(ctx, &bedrockruntime.InvokeModelWithResponseStreamInput{
+		Body:        jsonReq,
+		ModelId:     aws.String(string(bedrockModel)),
+		ContentType: aws.String("application/json"),
+	}, requestOptFns(ro)...)
+
+	if err != nil {
+		cancel()
+		return nil, toAPIError(err)
 	}
+
+	metadata := responseMetadata(ro, output.ResultMetadata, time.Since(start))
+
+	return handleStreaming(ctx, cancel, output, metadata, debugLogger)
 }
 
-func handleStreaming(ctx context.Context, output *bedrockruntime.InvokeModelWithResponseStreamOutput) (claude.MessageResponse, error) {
+// requestOptFns converts per-request header options into bedrock SDK options.
+func requestOptFns(ro *clientiface.RequestOptions) []func(*bedrockruntime.Options) {
+	headerOpts := *ro
+	headerOpts.Betas = nil // sent in the request body instead
+
+	h := make(http.Header)
+	request.ApplyHeaders(h, &headerOpts)
+	if len(h) == 0 {
+		return nil
+	}
 
-	ch := make(chan claude.MessageEvent)
-	meta := messageResponse{
-		responses: ch,
+	return []func(*bedrockruntime.Options){
+		func(o *bedrockruntime.Options) {
+			for k, vals := range h {
+				for _, v := range vals {
+					o.APIOptions = append(o.APIOptions, smithyhttp.AddHeaderValue(k, v))
+				}
+			}
+		},
 	}
+}
 
+// responseMetadata extracts the response metadata from the SDK result
+// and calls the per-request response hook, if set.
+func responseMetadata(ro *clientiface.RequestOptions, md middleware.Metadata, elapsed time.Duration) claude.ResponseMetadata {
+	metadata := claude.ResponseMetadata{
+		ProcessingTime: elapsed,
+	}
+	metadata.RequestID, _ = awsmiddleware.GetRequestIDMetadata(md)
+
+	raw, ok := awsmiddleware.GetRawResponse(md).(*smithyhttp.Response)
+	if !ok {
+		return metadata
+	}
+
+	metadata.StatusCode = raw.StatusCode
+	metadata.Header = raw.Header
+	if latency, err := strconv.Atoi(raw.Header.Get("x-amzn-bedrock-invocation-latency")); err == nil {
+		metadata.ProcessingTime = time.Duration(latency) * time.Millisecond
+	}
+
+	if ro.ResponseHook != nil {
+		ro.ResponseHook(raw.Response)
+	}
+
+	return metadata
+}
+
+// handleStreaming decodes the bedrock event stream into MessageEvents.
+// The stream is closed when ctx is done or the response is closed, and
+// a _client_error event is sent as the final event if the stream ends early.
+func handleStreaming(ctx context.Context, cancel context.CancelFunc, output *bedrockruntime.InvokeModelWithResponseStreamOutput, metadata claude.ResponseMetadata, debugLogger *slog.Logger) (claude.MessageResponse, error) {
+	stream := output.GetStream()
+	s := responseparser.NewStream(metadata, stream.Close)
+	done := make(chan struct{})
+
+	// closing the stream unblocks the Events reader when ctx is canceled
 	go func() {
-		defer close(ch)
+		select {
+		case <-ctx.Done():
+			stream.Close()
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer s.Finish()
+		defer cancel()
+		defer close(done)
+
+		clientError := func(err error) claude.MessageEvent {
+			return claude.MessageEvent{
+				Type: "_client_error",
+				Data: claude.NewClientError(err),
+			}
+		}
+
+		var complete bool
+		for event := range stream.Events() {
+			if debugLogger != nil && debugLogger.Enabled(ctx, slog.LevelDebug) {
+				debugLogger.Debug("bedrock event", "event", event)
+			}
 
-		for event := range output.GetStream().Events() {
 			switch v := event.(type) {
 			case *types.ResponseStreamMemberChunk:
 
 				var msg claude.MessageEvent
 				err := json.NewDecoder(bytes.NewReader(v.Value.Bytes)).Decode(&msg)
 				if err != nil {
-					msg = claude.MessageEvent{
-						Type: "_client_error",
-						Data: claude.NewClientError(fmt.Errorf("decode event json error: %w", err)),
-					}
-					select {
-					case ch <- msg:
-					case <-ctx.Done():
-					}
-
+					s.SendFinal(ctx, clientError(fmt.Errorf("decode event json error: %w", err)))
 					return
 				}
 
@@ -151,70 +266,42 @@ func handleStreaming(ctx context.Context, output *bedrockruntime.InvokeModelWith
 				case "message_stop":
 					msg.Data = &claude.MessageStop{}
 				default:
-					msg = claude.MessageEvent{
-						Type: "_client_error",
-						Data: claude.NewClientError(fmt.Errorf("unknown event type: %s", msg.Type)),
-					}
-					select {
-					case ch <- msg:
-					case <-ctx.Done():
-					}
-
+					s.SendFinal(ctx, clientError(fmt.Errorf("unknown event type: %s", msg.Type)))
 					return
 				}
 
 				err = json.NewDecoder(bytes.NewReader(v.Value.Bytes)).Decode(&msg.Data)
 				if err != nil {
-					msg = claude.MessageEvent{
-						Type: "_client_error",
-						Data: claude.NewClientError(fmt.Errorf("decode event json error: %w", err)),
-					}
-					select {
-					case ch <- msg:
-					case <-ctx.Done():
-					}
-
+					s.SendFinal(ctx, clientError(fmt.Errorf("decode event json error: %w", err)))
 					return
 				}
 
-				select {
-				case ch <- msg:
-				case <-ctx.Done():
+				complete = msg.Type == "message_stop"
+
+				if !s.Send(ctx, msg) {
+					if ctx.Err() != nil {
+						s.SendFinal(ctx, clientError(ctx.Err()))
+					}
 					return
 				}
 			case *types.UnknownUnionMember:
-				msg := claude.MessageEvent{
-					Type: "_client_error",
-					Data: claude.NewClientError(fmt.Errorf("unknown bedrock tag: %s", v.Tag)),
-				}
-				select {
-				case ch <- msg:
-				case <-ctx.Done():
-				}
-
+				s.SendFinal(ctx, clientError(fmt.Errorf("unknown bedrock tag: %s", v.Tag)))
 				return
 			default:
-				msg := claude.MessageEvent{
-					Type: "_client_error",
-					Data: claude.NewClientError(fmt.Errorf("unknown bedrock event type: %T %+v", v, v)),
-				}
-				select {
-				case ch <- msg:
-				case <-ctx.Done():
-				}
-
+				s.SendFinal(ctx, clientError(fmt.Errorf("unknown bedrock event type: %T %+v", v, v)))
 				return
 			}
 		}
-	}()
-
-	return &meta, nil
-}
 
-type messageResponse struct {
-	responses <-chan claude.MessageEvent
-}
+		if complete {
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			s.SendFinal(ctx, clientError(err))
+		} else if err := stream.Err(); err != nil {
+			s.SendFinal(ctx, clientError(fmt.Errorf("bedrock stream error: %w", toAPIError(err))))
+		}
+	}()
 
-func (m *messageResponse) Responses() <-chan claude.MessageEvent {
-	return m.responses
+	return s, nil
 }
@@ -0,0 +1,345 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/google/go-cmp/cmp"
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+)
+
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	br := bedrockruntime.New(bedrockruntime.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+	})
+	return NewClient(br, opts...)
+}
+
+func TestMessageGeography(t *testing.T) {
+	var gotPath string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","model":"claude-sonnet-4-5-20250929","content":[{"type":"text","text":"Hello!"}],"stop_reason":"end_turn","stop_sequence":null,"usage":{"input_tokens":10,"output_tokens":3}}`)
+	}), WithGeography(GeographyEU))
+
+	tests := []struct {
+		model  string
+		expect string
+	}{
+		{claude.ClaudeSonnet4Dot5Latest, "/model/eu.anthropic.claude-sonnet-4-5-20250929-v1%3A0/invoke"},
+		{"us.anthropic.claude-sonnet-4-5-20250929-v1:0", "/model/us.anthropic.claude-sonnet-4-5-20250929-v1%3A0/invoke"},
+	}
+
+	for _, tt := range tests {
+		req := &claude.MessageRequest{
+			Model: tt.model,
+			Messages: []claude.MessageTurn{
+				{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+			},
+		}
+		resp, err := client.Message(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := claude.Accumulate(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Text() != "Hello!" {
+			t.Errorf("unexpected response text: %q", msg.Text())
+		}
+		if gotPath != tt.expect {
+			t.Errorf("model %s: request path = %s, want %s", tt.model, gotPath, tt.expect)
+		}
+	}
+	// claude 2.1 has no cross-region inference profile
+	req := &claude.MessageRequest{
+		Model: claude.Claude2Dot1,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	if _, err := client.Message(context.Background(), req); err == nil {
+		t.Errorf("expected error for model without an eu inference profile")
+	}
+}
+
+// writeChunk writes a bedrock response stream chunk event containing data.
+func writeChunk(t *testing.T, w io.Writer, data string) {
+	t.Helper()
+	payload, err := json.Marshal(map[string][]byte{"bytes": []byte(data)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var headers eventstream.Headers
+	headers.Set(":message-type", eventstream.StringValue("event"))
+	headers.Set(":event-type", eventstream.StringValue("chunk"))
+	headers.Set(":content-type", eventstream.StringValue("application/json"))
+	err = eventstream.NewEncoder().Encode(w, eventstream.Message{Headers: headers, Payload: payload})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.(http.Flusher).Flush()
+}
+
+func TestMessageStreamCancel(t *testing.T) {
+	serverDone := make(chan struct{})
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(serverDone)
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "application/vnd.amazon.eventstream")
+		writeChunk(t, w, `{"type":"message_start","message":{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[],"stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":10,"output_tokens":1}}}`)
+		writeChunk(t, w, `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`)
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			t.Error("request was not canceled")
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req := &claude.MessageRequest{
+		Model:  claude.Claude3Haiku,
+		Stream: true,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	resp, err := client.Message(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := <-resp.Responses()
+	if first.Type != "message_start" {
+		t.Fatalf("unexpected first event: %+v", first)
+	}
+
+	// stop reading and cancel; the stream must still end with a terminal event
+	cancel()
+
+	var last claude.MessageEvent
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case evt, ok := <-resp.Responses():
+			if !ok {
+				done = true
+				break
+			}
+			last = evt
+		case <-timeout:
+			t.Fatal("response channel was not closed after cancel")
+		}
+	}
+
+	clientErr, ok := last.Data.(*claude.ClientError)
+	if !ok || !errors.Is(clientErr, context.Canceled) {
+		t.Fatalf("expected context.Canceled terminal event, got %+v", last)
+	}
+	if !errors.Is(resp.Err(), context.Canceled) {
+		t.Fatalf("expected Err to be context.Canceled, got %v", resp.Err())
+	}
+
+	select {
+	case <-serverDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("http request was not canceled")
+	}
+}
+
+func TestMessageStreamClose(t *testing.T) {
+	serverDone := make(chan struct{})
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(serverDone)
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "application/vnd.amazon.eventstream")
+		writeChunk(t, w, `{"type":"message_start","message":{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[],"stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":10,"output_tokens":1}}}`)
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			t.Error("connection was not closed")
+		}
+	}))
+
+	req := &claude.MessageRequest{
+		Model:  claude.Claude3Haiku,
+		Stream: true,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	resp, err := client.Message(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := <-resp.Responses()
+	if first.Type != "message_start" {
+		t.Fatalf("unexpected first event: %+v", first)
+	}
+
+	resp.Close()
+
+	if !errors.Is(resp.Err(), claude.ErrResponseClosed) {
+		t.Fatalf("expected ErrResponseClosed, got %v", resp.Err())
+	}
+	for evt := range resp.Responses() {
+		t.Fatalf("unexpected event after close: %+v", evt)
+	}
+
+	select {
+	case <-serverDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("http request was not released")
+	}
+}
+
+func TestMessageReuseRequest(t *testing.T) {
+	var gotPaths []string
+	var gotBetas [][]string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			AnthropicBeta []string `json:"anthropic_beta"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		gotPaths = append(gotPaths, r.URL.EscapedPath())
+		gotBetas = append(gotBetas, body.AnthropicBeta)
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Hello!"}],"stop_reason":"end_turn","stop_sequence":null,"usage":{"input_tokens":10,"output_tokens":3}}`)
+	}))
+
+	req := &claude.MessageRequest{
+		Model:         claude.Claude3Haiku,
+		AnthropicBeta: []string{"token-efficient-tools-2025-02-19"},
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+
+	for _, opts := range [][]clientiface.Option{
+		{clientiface.WithBeta("some-beta-2025-01-01")},
+		nil,
+	} {
+		resp, err := client.Message(context.Background(), req, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := claude.Accumulate(resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectBetas := [][]string{
+		{"token-efficient-tools-2025-02-19", "some-beta-2025-01-01"},
+		{"token-efficient-tools-2025-02-19"},
+	}
+	if diff := cmp.Diff(expectBetas, gotBetas); diff != "" {
+		t.Errorf("anthropic_beta mismatch (-want +got):\n%s", diff)
+	}
+	if gotPaths[0] != gotPaths[1] {
+		t.Errorf("request paths differ: %v", gotPaths)
+	}
+	if req.Model != claude.Claude3Haiku || len(req.AnthropicBeta) != 1 {
+		t.Errorf("request was modified: model=%q betas=%v", req.Model, req.AnthropicBeta)
+	}
+}
+
+func TestMessageTimeout(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if r.URL.Path == "/model/anthropic.claude-3-haiku-20240307-v1:0/invoke" {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("content-type", "application/vnd.amazon.eventstream")
+		writeChunk(t, w, `{"type":"message_start","message":{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[],"stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":10,"output_tokens":1}}}`)
+		<-r.Context().Done()
+	}))
+
+	for _, stream := range []bool{false, true} {
+		req := &claude.MessageRequest{
+			Model:  claude.Claude3Haiku,
+			Stream: stream,
+			Messages: []claude.MessageTurn{
+				{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+			},
+		}
+
+		start := time.Now()
+		resp, err := client.Message(context.Background(), req, clientiface.WithTimeout(100*time.Millisecond))
+		if err == nil {
+			_, err = claude.Accumulate(resp)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("stream=%t: expected context.DeadlineExceeded, got %v", stream, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("stream=%t: timeout not honored, took %s", stream, elapsed)
+		}
+	}
+}
+
+func TestMessageStreamErrorAfterBufferedEvent(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "application/vnd.amazon.eventstream")
+		writeChunk(t, w, `{"type":"message_start","message":{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[],"stop_reason":null,"stop_sequence":null,"usage":{"input_tokens":10,"output_tokens":1}}}`)
+		writeChunk(t, w, `{"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":5}}`)
+		writeChunk(t, w, `{"type":"bogus_event"}`)
+	}))
+
+	req := &claude.MessageRequest{
+		Model:  claude.Claude3Haiku,
+		Stream: true,
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+	resp, err := client.Message(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	if evt := <-resp.Responses(); evt.Type != "message_start" {
+		t.Fatalf("unexpected first event: %+v", evt)
+	}
+
+	// give the stream time to buffer message_delta and hit the error
+	time.Sleep(50 * time.Millisecond)
+
+	var types []string
+	for evt := range resp.Responses() {
+		types = append(types, evt.Type)
+	}
+	if diff := cmp.Diff([]string{"message_delta", "_client_error"}, types); diff != "" {
+		t.Errorf("event mismatch (-want +got):\n%s", diff)
+	}
+	if resp.Err() == nil {
+		t.Errorf("expected Err to be set")
+	}
+}
@@ -0,0 +1,69 @@
+package bedrock
+
+import (
+	"errors"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/smithy-go"
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/internal/responseparser"
+)
+
+// toAPIError converts bedrock API errors into a *claude.APIError with
+// the equivalent Anthropic error type. The original error is available
+// via errors.As/errors.Unwrap. Other errors are returned unchanged.
+func toAPIError(err error) error {
+	var genericErr smithy.APIError
+	var responseErr *awshttp.ResponseError
+	hasGeneric := errors.As(err, &genericErr)
+	hasResponse := errors.As(err, &responseErr)
+	if !hasGeneric && !hasResponse {
+		return err
+	}
+
+	apiErr := &claude.APIError{
+		Message: err.Error(),
+		Cause:   err,
+	}
+	if hasGeneric {
+		apiErr.Message = genericErr.ErrorMessage()
+	}
+	if hasResponse {
+		apiErr.StatusCode = responseErr.HTTPStatusCode()
+		apiErr.RequestID = responseErr.ServiceRequestID()
+		if responseErr.Response != nil {
+			apiErr.RetryAfter = responseparser.ParseRetryAfter(responseErr.Response.Header.Get("retry-after"))
+		}
+	}
+
+	var (
+		throttling   *types.ThrottlingException
+		quota        *types.ServiceQuotaExceededException
+		notReady     *types.ModelNotReadyException
+		internal     *types.InternalServerException
+		modelTimeout *types.ModelTimeoutException
+		validation   *types.ValidationException
+		accessDenied *types.AccessDeniedException
+		notFound     *types.ResourceNotFoundException
+	)
+
+	switch {
+	case errors.As(err, &throttling), errors.As(err, &quota):
+		apiErr.Type = claude.ErrorTypeRateLimit
+	case errors.As(err, &notReady):
+		apiErr.Type = claude.ErrorTypeOverloaded
+	case errors.As(err, &internal), errors.As(err, &modelTimeout):
+		apiErr.Type = claude.ErrorTypeAPI
+	case errors.As(err, &validation):
+		apiErr.Type = claude.ErrorTypeInvalidRequest
+	case errors.As(err, &accessDenied):
+		apiErr.Type = claude.ErrorTypePermission
+	case errors.As(err, &notFound):
+		apiErr.Type = claude.ErrorTypeNotFound
+	default:
+		apiErr.Type = claude.ErrorTypeForStatus(apiErr.StatusCode)
+	}
+
+	return apiErr
+}
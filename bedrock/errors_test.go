@@ -0,0 +1,37 @@
+package bedrock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/psanford/claude"
+)
+
+func TestToAPIError(t *testing.T) {
+	throttled := &types.ThrottlingException{Message: aws.String("Too many requests")}
+	err := toAPIError(throttled)
+
+	var apiErr *claude.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %T", err)
+	}
+	if !apiErr.IsRateLimit() || apiErr.Message != "Too many requests" {
+		t.Fatalf("unexpected APIError: %+v", apiErr)
+	}
+
+	var cause *types.ThrottlingException
+	if !errors.As(err, &cause) {
+		t.Fatal("expected original bedrock error to be preserved")
+	}
+
+	if !claude.IsInvalidRequest(toAPIError(&types.ValidationException{Message: aws.String("bad")})) {
+		t.Fatal("expected ValidationException to map to invalid_request_error")
+	}
+
+	plain := errors.New("dial tcp: connection refused")
+	if toAPIError(plain) != plain {
+		t.Fatal("expected non-API errors to be returned unchanged")
+	}
+}
@@ -2,6 +2,9 @@ package bedrock
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/psanford/claude"
 )
@@ -11,6 +14,12 @@ import (
 type BedrockModel string
 
 const (
+	ClaudeOpus4Dot5     BedrockModel = "anthropic.claude-opus-4-5-20251101-v1:0"
+	ClaudeHaiku4Dot5    BedrockModel = "anthropic.claude-haiku-4-5-20251001-v1:0"
+	ClaudeSonnet4Dot5   BedrockModel = "anthropic.claude-sonnet-4-5-20250929-v1:0"
+	ClaudeOpus4Dot1     BedrockModel = "anthropic.claude-opus-4-1-20250805-v1:0"
+	ClaudeOpus4         BedrockModel = "anthropic.claude-opus-4-20250514-v1:0"
+	ClaudeSonnet4       BedrockModel = "anthropic.claude-sonnet-4-20250514-v1:0"
 	Claude3Dot7Sonnet   BedrockModel = "anthropic.claude-3-7-sonnet-20250219-v1:0"
 	Claude3Dot5SonnetV2 BedrockModel = "anthropic.claude-3-5-sonnet-20241022-v2:0"
 	Claude3Dot5Haiku    BedrockModel = "anthropic.claude-3-5-haiku-20241022-v1:0"
@@ -23,69 +32,137 @@ const (
 	Claude1Dot2Instant  BedrockModel = "anthropic.claude-instant-v1"
 )
 
-var Models = []BedrockModel{
-	Claude3Dot7Sonnet,
-	Claude3Dot5SonnetV2,
-	Claude3Dot5Haiku,
-	Claude3Dot5Sonnet,
-	Claude3Opus,
-	Claude3Sonnet,
-	Claude3Haiku,
-	Claude2Dot1,
-	Clause2Dot0,
-	Claude1Dot2Instant,
+// Models is the list of models built into the claude model registry that are available on Bedrock.
+// Use claude.FindModels to include models added with claude.RegisterModel.
+var Models = registeredModels()
+
+func registeredModels() []BedrockModel {
+	var models []BedrockModel
+	for _, id := range claude.Models() {
+		if m, _ := claude.LookupModel(id); m.BedrockID != "" {
+			models = append(models, BedrockModel(m.BedrockID))
+		}
+	}
+	return models
+}
+
+// legacyPrettyNames keeps the names PrettyName returned for these models
+// before it used the model registry.
+var legacyPrettyNames = map[BedrockModel]string{
+	Claude3Dot7Sonnet:   "Claude 3.7 Sonnet",
+	Claude3Dot5SonnetV2: "Claude 3.5 Sonnet v2",
+	Claude3Dot5Haiku:    "Claude 3.5 Haiku",
+	Claude3Dot5Sonnet:   "Claude 3.5 Sonnet",
+	Claude3Opus:         "Claude 3 Opus",
+	Claude3Sonnet:       "Claude 3 Sonnet",
+	Claude3Haiku:        "Claude 3 Haiku",
+	Claude2Dot1:         "Claude 2.1",
+	Clause2Dot0:         "Claude 2.0",
+	Claude1Dot2Instant:  "Claude 1.2 Instant",
 }
 
 func (m BedrockModel) PrettyName() string {
-	switch m {
-	case Claude3Dot7Sonnet:
-		return "Claude 3.7 Sonnet"
-	case Claude3Dot5SonnetV2:
-		return "Claude 3.5 Sonnet v2"
-	case Claude3Dot5Haiku:
-		return "Claude 3.5 Haiku"
-	case Claude3Dot5Sonnet:
-		return "Claude 3.5 Sonnet"
-	case Claude3Opus:
-		return "Claude 3 Opus"
-	case Claude3Sonnet:
-		return "Claude 3 Sonnet"
-	case Claude3Haiku:
-		return "Claude 3 Haiku"
-	case Claude2Dot1:
-		return "Claude 2.1"
-	case Clause2Dot0:
-		return "Claude 2.0"
-	case Claude1Dot2Instant:
-		return "Claude 1.2 Instant"
-	default:
-		return fmt.Sprintf("Unknown BedrockModel<%s>", m)
+	if geo, rest, ok := splitGeography(string(m)); ok {
+		if name, ok := BedrockModel(rest).prettyName(); ok {
+			return fmt.Sprintf("%s (%s)", name, geo)
+		}
 	}
+	if name, ok := m.prettyName(); ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown BedrockModel<%s>", m)
 }
 
+func (m BedrockModel) prettyName() (string, bool) {
+	if name, ok := legacyPrettyNames[m]; ok {
+		return name, true
+	}
+	if info, ok := claude.LookupModel(string(m)); ok && info.BedrockID == string(m) {
+		return info.DisplayName, true
+	}
+	return "", false
+}
+
+// Geography is the prefix of a cross-region inference profile ID.
+// Requests to a cross-region inference profile are routed to any region
+// in the geography.
+type Geography string
+
+const (
+	GeographyUS     Geography = "us"
+	GeographyEU     Geography = "eu"
+	GeographyAPAC   Geography = "apac"
+	GeographyUSGov  Geography = "us-gov"
+	GeographyGlobal Geography = "global"
+)
+
+var geographies = []Geography{GeographyUS, GeographyEU, GeographyAPAC, GeographyUSGov, GeographyGlobal}
+
+// InferenceProfile returns the cross-region inference profile ID for the model in geo.
+// Inference profile IDs and ARNs are returned unchanged. It returns an error if the
+// claude model registry doesn't list an inference profile for the model in geo.
+func (m BedrockModel) InferenceProfile(geo Geography) (BedrockModel, error) {
+	if _, _, ok := splitGeography(string(m)); ok || IsARN(string(m)) {
+		return m, nil
+	}
+	info, ok := claude.LookupModel(string(m))
+	if !ok || info.BedrockID != string(m) {
+		return m, fmt.Errorf("Unknown bedrock model: %s", m)
+	}
+	if !slices.Contains(info.BedrockGeographies, string(geo)) {
+		return m, fmt.Errorf("No %s inference profile for model: %s", geo, m)
+	}
+	return BedrockModel(string(geo) + "." + string(m)), nil
+}
+
+// splitGeography splits a cross-region inference profile ID into its
+// geography and foundation model ID.
+func splitGeography(id string) (Geography, string, bool) {
+	for _, geo := range geographies {
+		if rest, ok := strings.CutPrefix(id, string(geo)+"."); ok {
+			return geo, rest, true
+		}
+	}
+	return "", id, false
+}
+
+// arnRE matches the bedrock resource ARNs that can be used as a model ID.
+var arnRE = regexp.MustCompile(`^arn:aws(-[a-z]+)*:bedrock:[a-z0-9-]+:([0-9]{12})?:(foundation-model|inference-profile|application-inference-profile|provisioned-model|custom-model)/[A-Za-z0-9.:/_-]+$`)
+
+// IsARN reports whether m looks like an ARN rather than a model ID.
+func IsARN(m string) bool {
+	return strings.HasPrefix(m, "arn:")
+}
+
+// ModelToBedrockModel maps a model ID, alias or Bedrock model ID to its
+// Bedrock model ID using the claude model registry.
+//
+// Cross-region inference profile IDs (e.g. "us.anthropic.claude-sonnet-4-5-20250929-v1:0")
+// are accepted if the registry lists a profile for the model in that geography. Foundation model, inference profile,
+// application inference profile, provisioned throughput and custom model ARNs are
+// passed through after checking that they are well formed.
 func ModelToBedrockModel(m string) (BedrockModel, error) {
-	switch m {
-	case claude.Claude3Dot7SonnetLatest, claude.Claude3Dot7Sonnet2502, string(Claude3Dot7Sonnet):
-		return Claude3Dot7Sonnet, nil
-	case claude.Claude3Dot5Sonnet2410, string(Claude3Dot5SonnetV2):
-		return Claude3Dot5SonnetV2, nil
-	case claude.Claude3Dot5Haiku, string(Claude3Dot5Haiku):
-		return Claude3Dot5Haiku, nil
-	case claude.Claude3Dot5Sonnet, string(Claude3Dot5Sonnet):
-		return Claude3Dot5Sonnet, nil
-	case claude.Claude3Opus, string(Claude3Opus):
-		return Claude3Opus, nil
-	case claude.Claude3Sonnet, string(Claude3Sonnet):
-		return Claude3Sonnet, nil
-	case claude.Claude3Haiku, string(Claude3Haiku):
-		return Claude3Haiku, nil
-	case claude.Claude2Dot1, string(Claude2Dot1):
-		return Claude2Dot1, nil
-	case claude.Clause2Dot0, string(Clause2Dot0):
-		return Clause2Dot0, nil
-	case claude.Claude1Dot2Instant, string(Claude1Dot2Instant):
-		return Claude1Dot2Instant, nil
+	if IsARN(m) {
+		if !arnRE.MatchString(m) {
+			return BedrockModel(m), fmt.Errorf("Invalid bedrock model ARN: %s", m)
+		}
+		return BedrockModel(m), nil
 	}
 
-	return BedrockModel(m), fmt.Errorf("Unknown model: %s", m)
+	if geo, rest, ok := splitGeography(m); ok {
+		info, ok := claude.LookupModel(rest)
+		if !ok || info.BedrockID != rest {
+			return BedrockModel(m), fmt.Errorf("Unknown model in inference profile: %s", m)
+		}
+		return BedrockModel(info.BedrockID).InferenceProfile(geo)
+	}
+
+	info, ok := claude.LookupModel(m)
+	if !ok {
+		return BedrockModel(m), fmt.Errorf("Unknown model: %s", m)
+	}
+	if info.BedrockID == "" {
+		return BedrockModel(m), fmt.Errorf("Model not available on bedrock: %s", m)
+	}
+	return BedrockModel(info.BedrockID), nil
 }
@@ -0,0 +1,108 @@
+package bedrock
+
+import (
+	"testing"
+
+	"github.com/psanford/claude"
+)
+
+func TestModelToBedrockModel(t *testing.T) {
+	tests := []struct {
+		model   string
+		want    BedrockModel
+		wantErr bool
+	}{
+		{model: claude.Claude3Dot5SonnetLatest, want: Claude3Dot5SonnetV2},
+		{model: claude.Claude3Dot5HaikuLatest, want: Claude3Dot5Haiku},
+		{model: claude.ClaudeSonnet4Dot5Latest, want: ClaudeSonnet4Dot5},
+		{model: claude.ClaudeOpus4, want: ClaudeOpus4},
+		{model: string(Claude3Haiku), want: Claude3Haiku},
+		{model: claude.Claude2Dot1, want: Claude2Dot1},
+		{model: "claude-sonet-4-5", wantErr: true},
+		{model: "us.anthropic.claude-sonnet-4-5-20250929-v1:0", want: "us.anthropic.claude-sonnet-4-5-20250929-v1:0"},
+		{model: "global.anthropic.claude-haiku-4-5-20251001-v1:0", want: "global.anthropic.claude-haiku-4-5-20251001-v1:0"},
+		{model: "us-gov.anthropic.claude-3-haiku-20240307-v1:0", want: "us-gov.anthropic.claude-3-haiku-20240307-v1:0"},
+		{model: "eu.anthropic.claude-sonet-4-5-20250929-v1:0", wantErr: true},
+		{model: "eu." + claude.ClaudeSonnet4Dot5, wantErr: true},
+		{model: "xx.anthropic.claude-sonnet-4-5-20250929-v1:0", wantErr: true},
+		// no cross-region inference profile
+		{model: "us-gov.anthropic.claude-v2", wantErr: true},
+		{model: "us.anthropic.claude-instant-v1", wantErr: true},
+		{model: "eu.anthropic.claude-opus-4-1-20250805-v1:0", wantErr: true},
+		{model: "arn:aws:bedrock:us-east-1:123456789012:application-inference-profile/a1b2c3d4e5f6", want: "arn:aws:bedrock:us-east-1:123456789012:application-inference-profile/a1b2c3d4e5f6"},
+		{model: "arn:aws:bedrock:us-west-2:123456789012:provisioned-model/abcdef123456", want: "arn:aws:bedrock:us-west-2:123456789012:provisioned-model/abcdef123456"},
+		{model: "arn:aws:bedrock:us-east-1::foundation-model/anthropic.claude-3-haiku-20240307-v1:0", want: "arn:aws:bedrock:us-east-1::foundation-model/anthropic.claude-3-haiku-20240307-v1:0"},
+		{model: "arn:aws:bedrock:us-east-1:123456789012:inference-profile/us.anthropic.claude-sonnet-4-20250514-v1:0", want: "arn:aws:bedrock:us-east-1:123456789012:inference-profile/us.anthropic.claude-sonnet-4-20250514-v1:0"},
+		{model: "arn:aws:bedrock:us-east-1:1234:provisioned-model/abc", wantErr: true},
+		{model: "arn:aws:s3:::my-bucket", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ModelToBedrockModel(tt.model)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ModelToBedrockModel(%q) error = %v, wantErr %v", tt.model, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ModelToBedrockModel(%q) = %s, want %s", tt.model, got, tt.want)
+		}
+	}
+
+	if got := ClaudeSonnet4Dot5.PrettyName(); got != "Claude Sonnet 4.5" {
+		t.Errorf("PrettyName() = %q", got)
+	}
+}
+
+func TestInferenceProfile(t *testing.T) {
+	tests := []struct {
+		model   BedrockModel
+		geo     Geography
+		want    BedrockModel
+		wantErr bool
+	}{
+		{model: ClaudeSonnet4Dot5, geo: GeographyEU, want: "eu.anthropic.claude-sonnet-4-5-20250929-v1:0"},
+		{model: ClaudeOpus4Dot5, geo: GeographyGlobal, want: "global.anthropic.claude-opus-4-5-20251101-v1:0"},
+		// existing profiles and ARNs are not changed
+		{model: "us.anthropic.claude-sonnet-4-5-20250929-v1:0", geo: GeographyEU, want: "us.anthropic.claude-sonnet-4-5-20250929-v1:0"},
+		{model: "arn:aws:bedrock:us-east-1:123456789012:application-inference-profile/a1b2c3d4e5f6", geo: GeographyEU, want: "arn:aws:bedrock:us-east-1:123456789012:application-inference-profile/a1b2c3d4e5f6"},
+		{model: Claude2Dot1, geo: GeographyUS, wantErr: true},
+		{model: Claude1Dot2Instant, geo: GeographyUSGov, wantErr: true},
+		{model: ClaudeOpus4Dot1, geo: GeographyEU, wantErr: true},
+		{model: "anthropic.claude-unknown-v1:0", geo: GeographyUS, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.model.InferenceProfile(tt.geo)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s.InferenceProfile(%s) error = %v, wantErr %v", tt.model, tt.geo, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%s.InferenceProfile(%s) = %s, want %s", tt.model, tt.geo, got, tt.want)
+		}
+	}
+
+	profile := BedrockModel("us.anthropic.claude-sonnet-4-5-20250929-v1:0")
+	if got := profile.PrettyName(); got != "Claude Sonnet 4.5 (us)" {
+		t.Errorf("PrettyName() = %q", got)
+	}
+}
+
+func TestPrettyName(t *testing.T) {
+	tests := []struct {
+		model BedrockModel
+		want  string
+	}{
+		{Claude3Dot7Sonnet, "Claude 3.7 Sonnet"},
+		{Claude3Dot5SonnetV2, "Claude 3.5 Sonnet v2"},
+		{Claude1Dot2Instant, "Claude 1.2 Instant"},
+		{ClaudeSonnet4Dot5, "Claude Sonnet 4.5"},
+		{"us.anthropic.claude-3-7-sonnet-20250219-v1:0", "Claude 3.7 Sonnet (us)"},
+		{"anthropic.claude-unknown", "Unknown BedrockModel<anthropic.claude-unknown>"},
+	}
+	for _, tt := range tests {
+		if got := tt.model.PrettyName(); got != tt.want {
+			t.Errorf("%s.PrettyName() = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
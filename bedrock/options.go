@@ -1,6 +1,10 @@
 package bedrock
 
-import "log/slog"
+import (
+	"log/slog"
+
+	"github.com/psanford/claude/clientiface"
+)
 
 type Option interface {
 	set(*Client)
@@ -19,3 +23,37 @@ func WithDebugLogger(l *slog.Logger) Option {
 		l: l,
 	}
 }
+
+type retryPolicyOption struct {
+	p *clientiface.RetryPolicy
+}
+
+func (o *retryPolicyOption) set(c *Client) {
+	c.retryPolicy = o.p
+}
+
+// WithRetryPolicy enables automatic retries of rate limit and overloaded
+// errors. See clientiface.DefaultRetryPolicy for a reasonable default.
+func WithRetryPolicy(p *clientiface.RetryPolicy) Option {
+	return &retryPolicyOption{
+		p: p,
+	}
+}
+
+type geographyOption struct {
+	geo Geography
+}
+
+func (o *geographyOption) set(c *Client) {
+	c.geography = o.geo
+}
+
+// WithGeography sends requests for canonical model names, such as
+// claude.ClaudeSonnet4Dot5, to the cross-region inference profile for geo.
+// Inference profile IDs and ARNs are used as is. Requests for models without
+// an inference profile in geo fail; see claude.ModelInfo.BedrockGeographies.
+func WithGeography(geo Geography) Option {
+	return &geographyOption{
+		geo: geo,
+	}
+}
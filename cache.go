@@ -0,0 +1,50 @@
+package claude
+
+// Prompt cache TTLs. See https://docs.anthropic.com/en/docs/build-with-claude/prompt-caching for details.
+const (
+	CacheTTL5Minutes = "5m"
+	CacheTTL1Hour    = "1h"
+)
+
+// CacheControl marks a content block, tool definition or system block
+// as a prompt cache breakpoint. Everything in the request up to and
+// including the marked block is cached.
+type CacheControl struct {
+	// Type is always "ephemeral".
+	Type string `json:"type"`
+	// TTL is the cache lifetime, CacheTTL5Minutes or CacheTTL1Hour.
+	// If empty the API default of 5 minutes is used.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// EphemeralCache returns an ephemeral CacheControl with the given ttl.
+// Pass an empty ttl to use the default.
+func EphemeralCache(ttl string) *CacheControl {
+	return &CacheControl{
+		Type: "ephemeral",
+		TTL:  ttl,
+	}
+}
+
+// Cacheable is implemented by content blocks that support cache_control.
+type Cacheable interface {
+	SetCacheControl(*CacheControl)
+}
+
+// WithCacheControl sets cc on the content block c and returns c.
+// Content blocks that don't support caching are returned unchanged.
+func WithCacheControl(c TurnContent, cc *CacheControl) TurnContent {
+	if cacheable, ok := c.(Cacheable); ok {
+		cacheable.SetCacheControl(cc)
+	}
+	return c
+}
+
+// SystemBlock is a text block in the system prompt.
+// See MessageRequest.SystemBlocks.
+type SystemBlock struct {
+	// Type is always "text". It is set automatically when the request is sent.
+	Type         string        `json:"type"`
+	Text         string        `json:"text"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
@@ -0,0 +1,309 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	CitationCharLocation         = "char_location"
+	CitationPageLocation         = "page_location"
+	CitationContentBlockLocation = "content_block_location"
+	CitationSearchResultLocation = "search_result_location"
+	CitationWebSearchResult      = "web_search_result_location"
+)
+
+// Citation is a reference from a text content block back to the part of a
+// source that supports it. It is one of *CharLocationCitation,
+// *PageLocationCitation, *ContentBlockLocationCitation,
+// *SearchResultLocationCitation, *WebSearchResultCitation or
+// *UnknownCitation.
+type Citation interface {
+	Type() string
+	// TextContent returns the text that was cited from the source.
+	TextContent() string
+}
+
+// CharLocationCitation cites a character range of a plain text document.
+// The end index is exclusive.
+type CharLocationCitation struct {
+	CitedText      string `json:"cited_text"`
+	DocumentIndex  int    `json:"document_index"`
+	DocumentTitle  string `json:"document_title,omitempty"`
+	StartCharIndex int    `json:"start_char_index"`
+	EndCharIndex   int    `json:"end_char_index"`
+}
+
+func (c *CharLocationCitation) Type() string {
+	return CitationCharLocation
+}
+
+func (c *CharLocationCitation) TextContent() string {
+	return c.CitedText
+}
+
+func (c *CharLocationCitation) MarshalJSON() ([]byte, error) {
+	type charLocationCitation CharLocationCitation
+	return marshalCitation(CitationCharLocation, (*charLocationCitation)(c))
+}
+
+// PageLocationCitation cites a page range of a PDF document.
+// Page numbers start at 1 and the end page is exclusive.
+type PageLocationCitation struct {
+	CitedText       string `json:"cited_text"`
+	DocumentIndex   int    `json:"document_index"`
+	DocumentTitle   string `json:"document_title,omitempty"`
+	StartPageNumber int    `json:"start_page_number"`
+	EndPageNumber   int    `json:"end_page_number"`
+}
+
+func (c *PageLocationCitation) Type() string {
+	return CitationPageLocation
+}
+
+func (c *PageLocationCitation) TextContent() string {
+	return c.CitedText
+}
+
+func (c *PageLocationCitation) MarshalJSON() ([]byte, error) {
+	type pageLocationCitation PageLocationCitation
+	return marshalCitation(CitationPageLocation, (*pageLocationCitation)(c))
+}
+
+// ContentBlockLocationCitation cites a range of blocks in a custom content document.
+// The end index is exclusive.
+type ContentBlockLocationCitation struct {
+	CitedText       string `json:"cited_text"`
+	DocumentIndex   int    `json:"document_index"`
+	DocumentTitle   string `json:"document_title,omitempty"`
+	StartBlockIndex int    `json:"start_block_index"`
+	EndBlockIndex   int    `json:"end_block_index"`
+}
+
+func (c *ContentBlockLocationCitation) Type() string {
+	return CitationContentBlockLocation
+}
+
+func (c *ContentBlockLocationCitation) TextContent() string {
+	return c.CitedText
+}
+
+func (c *ContentBlockLocationCitation) MarshalJSON() ([]byte, error) {
+	type contentBlockLocationCitation ContentBlockLocationCitation
+	return marshalCitation(CitationContentBlockLocation, (*contentBlockLocationCitation)(c))
+}
+
+// SearchResultLocationCitation cites a range of blocks in a search result.
+// The end index is exclusive.
+type SearchResultLocationCitation struct {
+	CitedText         string `json:"cited_text"`
+	Source            string `json:"source"`
+	Title             string `json:"title,omitempty"`
+	SearchResultIndex int    `json:"search_result_index"`
+	StartBlockIndex   int    `json:"start_block_index"`
+	EndBlockIndex     int    `json:"end_block_index"`
+}
+
+func (c *SearchResultLocationCitation) Type() string {
+	return CitationSearchResultLocation
+}
+
+func (c *SearchResultLocationCitation) TextContent() string {
+	return c.CitedText
+}
+
+func (c *SearchResultLocationCitation) MarshalJSON() ([]byte, error) {
+	type searchResultLocationCitation SearchResultLocationCitation
+	return marshalCitation(CitationSearchResultLocation, (*searchResultLocationCitation)(c))
+}
+
+// WebSearchResultCitation cites a result from the web search server tool.
+type WebSearchResultCitation struct {
+	CitedText string `json:"cited_text"`
+	URL       string `json:"url"`
+	Title     string `json:"title,omitempty"`
+	// EncryptedIndex must be passed back unmodified in multi-turn conversations.
+	EncryptedIndex string `json:"encrypted_index"`
+}
+
+func (c *WebSearchResultCitation) Type() string {
+	return CitationWebSearchResult
+}
+
+func (c *WebSearchResultCitation) TextContent() string {
+	return c.CitedText
+}
+
+func (c *WebSearchResultCitation) MarshalJSON() ([]byte, error) {
+	type webSearchResultCitation WebSearchResultCitation
+	return marshalCitation(CitationWebSearchResult, (*webSearchResultCitation)(c))
+}
+
+// UnknownCitation holds a citation of a type not known to this library.
+// It marshals back to the original JSON so it can be passed back
+// unmodified in multi-turn conversations.
+type UnknownCitation struct {
+	Typ string
+	Raw json.RawMessage
+}
+
+func (c *UnknownCitation) Type() string {
+	return c.Typ
+}
+
+// TextContent returns the cited_text field of the citation, if any.
+func (c *UnknownCitation) TextContent() string {
+	var citation struct {
+		CitedText string `json:"cited_text"`
+	}
+	json.Unmarshal(c.Raw, &citation)
+	return citation.CitedText
+}
+
+func (c *UnknownCitation) MarshalJSON() ([]byte, error) {
+	return c.Raw, nil
+}
+
+// marshalCitation encodes v with its type field added.
+func marshalCitation(typ string, v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	typeField := fmt.Sprintf(`{"type":%q`, typ)
+	if string(b) == "{}" {
+		return []byte(typeField + "}"), nil
+	}
+	return append([]byte(typeField+","), b[1:]...), nil
+}
+
+func unmarshalCitation(raw json.RawMessage) (Citation, error) {
+	var citationType struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &citationType); err != nil {
+		return nil, err
+	}
+
+	var c Citation
+	switch citationType.Type {
+	case CitationCharLocation:
+		c = &CharLocationCitation{}
+	case CitationPageLocation:
+		c = &PageLocationCitation{}
+	case CitationContentBlockLocation:
+		c = &ContentBlockLocationCitation{}
+	case CitationSearchResultLocation:
+		c = &SearchResultLocationCitation{}
+	case CitationWebSearchResult:
+		c = &WebSearchResultCitation{}
+	default:
+		// keep citations from newer API versions so they survive a round trip
+		return &UnknownCitation{
+			Typ: citationType.Type,
+			Raw: append(json.RawMessage(nil), raw...),
+		}, nil
+	}
+
+	if err := json.Unmarshal(raw, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func unmarshalCitations(raw []json.RawMessage) ([]Citation, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	citations := make([]Citation, len(raw))
+	for i, rawCitation := range raw {
+		c, err := unmarshalCitation(rawCitation)
+		if err != nil {
+			return nil, err
+		}
+		citations[i] = c
+	}
+	return citations, nil
+}
+
+// TextCitations returns the citations attached to a text content block.
+// It returns nil for other block types.
+func TextCitations(c TurnContent) []Citation {
+	if t, ok := c.(*turnContentText); ok {
+		return t.Citations
+	}
+	return nil
+}
+
+// TextWithFootnotes returns the message text with a footnote marker such as [1]
+// after each cited passage, followed by a list of the cited sources.
+// Identical citations share a footnote number.
+func (c *MessageStart) TextWithFootnotes() string {
+	var (
+		body  strings.Builder
+		notes []string
+		seen  = make(map[string]int)
+	)
+
+	for _, content := range c.Content {
+		body.WriteString(content.TextContent())
+		for _, citation := range TextCitations(content) {
+			note := footnote(citation)
+			n, ok := seen[note]
+			if !ok {
+				notes = append(notes, note)
+				n = len(notes)
+				seen[note] = n
+			}
+			fmt.Fprintf(&body, "[%d]", n)
+		}
+	}
+
+	if len(notes) == 0 {
+		return body.String()
+	}
+
+	body.WriteString("\n")
+	for i, note := range notes {
+		fmt.Fprintf(&body, "\n[%d] %s", i+1, note)
+	}
+	return body.String()
+}
+
+func footnote(c Citation) string {
+	var source string
+	switch c := c.(type) {
+	case *CharLocationCitation:
+		source = documentName(c.DocumentTitle, c.DocumentIndex)
+	case *PageLocationCitation:
+		source = documentName(c.DocumentTitle, c.DocumentIndex)
+		if c.EndPageNumber > c.StartPageNumber+1 {
+			source += fmt.Sprintf(", pp. %d-%d", c.StartPageNumber, c.EndPageNumber-1)
+		} else {
+			source += fmt.Sprintf(", p. %d", c.StartPageNumber)
+		}
+	case *ContentBlockLocationCitation:
+		source = documentName(c.DocumentTitle, c.DocumentIndex)
+	case *SearchResultLocationCitation:
+		source = c.Source
+		if c.Title != "" {
+			source = fmt.Sprintf("%s (%s)", c.Title, c.Source)
+		}
+	case *WebSearchResultCitation:
+		source = c.URL
+		if c.Title != "" {
+			source = fmt.Sprintf("%s (%s)", c.Title, c.URL)
+		}
+	case *UnknownCitation:
+		source = c.Typ
+	}
+	return fmt.Sprintf("%q - %s", strings.TrimSpace(c.TextContent()), source)
+}
+
+func documentName(title string, idx int) string {
+	if title != "" {
+		return title
+	}
+	return fmt.Sprintf("Document %d", idx)
+}
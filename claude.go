@@ -2,6 +2,7 @@ package claude
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -80,6 +81,10 @@ type MessageRequest struct {
 	// System prompt.
 	// A system prompt is a way of providing context and instructions to Claude, such as specifying a particular goal or role.
 	System string `json:"system,omitempty"`
+	// SystemBlocks is an alternative to System that expresses the system prompt as a list of text blocks.
+	// This allows marking parts of the system prompt as cache breakpoints.
+	// Only one of System or SystemBlocks may be set.
+	SystemBlocks []SystemBlock `json:"-"`
 	// The maximum number of tokens to generate before stopping.
 	// Note that models may stop before reaching this maximum.
 	// This parameter only specifies the absolute maximum number of tokens to generate.
@@ -112,36 +117,270 @@ type MessageRequest struct {
 	// AnthropicVersion is used for AWS Bedrock and GCP Vertex.
 	// The client implementations in this library will set this for you so you can leave it blank.
 	AnthropicVersion string `json:"anthropic_version,omitempty"`
+	// AnthropicBeta lists beta feature flags for AWS Bedrock, which takes them in the request body.
+	// The bedrock client sets this from the clientiface.WithBeta option so you can leave it blank.
+	AnthropicBeta []string `json:"anthropic_beta,omitempty"`
 	// How the model should use the provided tools.
 	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
 	// Definitions of tools that the model may use.
 	Tools []Tool `json:"tools,omitempty"`
+	// Configuration for enabling Claude's extended thinking.
+	// When enabled, responses include thinking content blocks showing Claude's thinking process before the final answer.
+	// Requires a minimum budget of 1,024 tokens and counts towards your max_tokens limit.
+	Thinking *ThinkingConfig `json:"thinking,omitempty"`
+}
+
+func (r MessageRequest) MarshalJSON() ([]byte, error) {
+	if err := r.validateToolChoice(); err != nil {
+		return nil, err
+	}
+
+	type messageRequest MessageRequest
+	if len(r.SystemBlocks) == 0 {
+		return json.Marshal(messageRequest(r))
+	}
+
+	if r.System != "" {
+		return nil, errors.New("only one of System or SystemBlocks may be set")
+	}
+
+	blocks := make([]SystemBlock, len(r.SystemBlocks))
+	for i, b := range r.SystemBlocks {
+		b.Type = TurnText
+		blocks[i] = b
+	}
+
+	return json.Marshal(struct {
+		messageRequest
+		System []SystemBlock `json:"system"`
+	}{
+		messageRequest: messageRequest(r),
+		System:         blocks,
+	})
+}
+
+// ThinkingConfig configures extended thinking.
+type ThinkingConfig struct {
+	// Type is either "enabled" or "disabled".
+	Type string `json:"type"`
+	// Determines how many tokens Claude can use for its internal reasoning process.
+	// Must be at least 1024 and less than MessageRequest.MaxTokens.
+	BudgetTokens int `json:"budget_tokens,omitempty"`
+}
+
+// ThinkingEnabled returns a ThinkingConfig that enables extended thinking
+// with the given token budget.
+func ThinkingEnabled(budgetTokens int) *ThinkingConfig {
+	return &ThinkingConfig{
+		Type:         "enabled",
+		BudgetTokens: budgetTokens,
+	}
 }
 
 // Tool defines a tool that the model may use.
 type Tool struct {
+	// Type is empty for custom tools. Anthropic-defined tools set it to one
+	// of the ToolType constants, e.g. ToolTypeBash20250124.
+	Type string `json:"type,omitempty"`
 	// Name of the tool.
 	Name string `json:"name"`
 	// Optional description of the tool.
 	Description string `json:"description,omitempty"`
 	// JSON schema for the tool input shape that the model will produce in tool_use output content blocks.
-	InputSchema any `json:"input_schema"`
+	// Required for custom tools.
+	InputSchema any `json:"input_schema,omitempty"`
+	// Display size for the computer use tool.
+	DisplayWidthPx  int `json:"display_width_px,omitempty"`
+	DisplayHeightPx int `json:"display_height_px,omitempty"`
+	// X11 display number for the computer use tool.
+	DisplayNumber *int `json:"display_number,omitempty"`
+	// Maximum number of characters to return when viewing a file with
+	// ToolTypeTextEditor20250728.
+	MaxCharacters int `json:"max_characters,omitempty"`
+	// Maximum number of times the web search tool may be used in a request.
+	MaxUses int `json:"max_uses,omitempty"`
+	// Only include web search results from these domains.
+	// Cannot be combined with BlockedDomains.
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+	// Never include web search results from these domains.
+	BlockedDomains []string `json:"blocked_domains,omitempty"`
+	// Localizes web search results.
+	UserLocation *UserLocation `json:"user_location,omitempty"`
+	// CacheControl marks this tool definition as a prompt cache breakpoint.
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
 }
 
+const (
+	// The model decides whether to use tools. This is the default when tools are provided.
+	ToolChoiceAuto = "auto"
+	// The model must use one of the provided tools.
+	ToolChoiceAny = "any"
+	// The model must use the tool named in ToolChoice.Name.
+	ToolChoiceTool = "tool"
+	// The model must not use any tools.
+	ToolChoiceNone = "none"
+)
+
 // ToolChoice defines how the model should use the provided tools.
 type ToolChoice struct {
-	// Specifies that the model should use a specific tool.
-	Tool string `json:"tool,omitempty"`
-	// Specifies that the model should use any available tool.
-	Any bool `json:"any,omitempty"`
-	// Specifies that the model should decide which tool to use.
-	Auto bool `json:"auto,omitempty"`
+	// Type is one of ToolChoiceAuto, ToolChoiceAny, ToolChoiceTool or ToolChoiceNone.
+	Type string `json:"type"`
+	// Name of the tool to use. Required when Type is ToolChoiceTool.
+	Name string `json:"name,omitempty"`
+	// Limit the model to at most one tool use per response (auto) or exactly
+	// one tool use (any, tool). Not valid with ToolChoiceNone.
+	DisableParallelToolUse bool `json:"disable_parallel_tool_use,omitempty"`
+
+	// Deprecated: set Type to ToolChoiceTool and Name instead.
+	Tool string `json:"-"`
+	// Deprecated: set Type to ToolChoiceAny instead.
+	Any bool `json:"-"`
+	// Deprecated: set Type to ToolChoiceAuto instead.
+	Auto bool `json:"-"`
+}
+
+// AutoToolChoice lets the model decide whether to use tools.
+func AutoToolChoice() *ToolChoice {
+	return &ToolChoice{Type: ToolChoiceAuto}
+}
+
+// AnyToolChoice requires the model to use one of the provided tools.
+func AnyToolChoice() *ToolChoice {
+	return &ToolChoice{Type: ToolChoiceAny}
+}
+
+// NamedToolChoice requires the model to use the named tool.
+func NamedToolChoice(name string) *ToolChoice {
+	return &ToolChoice{Type: ToolChoiceTool, Name: name}
+}
+
+// NoneToolChoice prevents the model from using any tools.
+func NoneToolChoice() *ToolChoice {
+	return &ToolChoice{Type: ToolChoiceNone}
+}
+
+// resolve returns the tool choice with the deprecated fields folded into Type and Name.
+func (t ToolChoice) resolve() (ToolChoice, error) {
+	var legacy []string
+	if t.Auto {
+		legacy = append(legacy, ToolChoiceAuto)
+	}
+	if t.Any {
+		legacy = append(legacy, ToolChoiceAny)
+	}
+	if t.Tool != "" {
+		legacy = append(legacy, ToolChoiceTool)
+	}
+
+	switch {
+	case len(legacy) > 1:
+		return t, errors.New("tool_choice: only one of Auto, Any or Tool may be set")
+	case len(legacy) == 1:
+		if t.Type != "" && t.Type != legacy[0] {
+			return t, fmt.Errorf("tool_choice: type %q conflicts with deprecated %s field", t.Type, legacy[0])
+		}
+		if t.Tool != "" && t.Name != "" && t.Tool != t.Name {
+			return t, fmt.Errorf("tool_choice: name %q conflicts with tool %q", t.Name, t.Tool)
+		}
+		t.Type = legacy[0]
+		if t.Tool != "" {
+			t.Name = t.Tool
+		}
+	}
+
+	return t, nil
+}
+
+// Validate reports whether the tool choice is well formed.
+func (t ToolChoice) Validate() error {
+	t, err := t.resolve()
+	if err != nil {
+		return err
+	}
+
+	switch t.Type {
+	case ToolChoiceAuto, ToolChoiceAny, ToolChoiceNone:
+		if t.Name != "" {
+			return fmt.Errorf("tool_choice: name is only valid with type %q", ToolChoiceTool)
+		}
+	case ToolChoiceTool:
+		if t.Name == "" {
+			return fmt.Errorf("tool_choice: type %q requires a name", ToolChoiceTool)
+		}
+	case "":
+		return errors.New("tool_choice: type is required")
+	default:
+		return fmt.Errorf("tool_choice: unknown type %q", t.Type)
+	}
+
+	if t.Type == ToolChoiceNone && t.DisableParallelToolUse {
+		return fmt.Errorf("tool_choice: disable_parallel_tool_use is not valid with type %q", ToolChoiceNone)
+	}
+
+	return nil
+}
+
+func (t ToolChoice) MarshalJSON() ([]byte, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	t, _ = t.resolve()
+
+	type toolChoice ToolChoice
+	return json.Marshal(toolChoice(t))
+}
+
+// validateToolChoice checks the tool choice against the rest of the request.
+func (r *MessageRequest) validateToolChoice() error {
+	if r.ToolChoice == nil {
+		return nil
+	}
+	if err := r.ToolChoice.Validate(); err != nil {
+		return err
+	}
+	tc, _ := r.ToolChoice.resolve()
+
+	if tc.Type == ToolChoiceTool && len(r.Tools) > 0 {
+		found := false
+		for _, tool := range r.Tools {
+			if tool.Name == tc.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("tool_choice: tool %q is not in the request tools", tc.Name)
+		}
+	}
+
+	if r.Thinking != nil && r.Thinking.Type == "enabled" && (tc.Type == ToolChoiceAny || tc.Type == ToolChoiceTool) {
+		return fmt.Errorf("tool_choice: type %q is not supported with extended thinking", tc.Type)
+	}
+
+	return nil
 }
 
 type MessageResponse interface {
 	Responses() <-chan MessageEvent
+	// Metadata returns information about the http response such as
+	// the request ID and rate limit headers.
+	Metadata() ResponseMetadata
+	// Close stops the response early, releasing the underlying connection
+	// and goroutines. The Responses channel is closed by the time Close returns.
+	// It is safe to call Close more than once and after the response has
+	// been fully read.
+	Close() error
+	// Err returns the error that ended the response, if any. This is the
+	// *ClaudeError or *ClientError from the final error event, or
+	// ErrResponseClosed if Close was called before the response completed.
+	// It should be called after the Responses channel has been closed.
+	Err() error
 }
 
+// ErrResponseClosed is returned by MessageResponse.Err when the response
+// was closed before it completed.
+var ErrResponseClosed = errors.New("response closed")
+
 type MessageStart struct {
 	ID           string        `json:"id"`
 	Type         string        `json:"type"`
@@ -150,10 +389,19 @@ type MessageStart struct {
 	Model        string        `json:"model"`
 	StopReason   string        `json:"stop_reason"`
 	StopSequence *string       `json:"stop_sequence"`
-	Usage        struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-	} `json:"usage"`
+	Usage        Usage         `json:"usage"`
+}
+
+// Usage is the billing and rate-limit usage of a message.
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	// The number of input tokens used to create the cache entry.
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	// The number of input tokens read from the cache.
+	CacheReadInputTokens int `json:"cache_read_input_tokens"`
+	// Server tool invocation counts. Only set when server tools were used.
+	ServerToolUse *ServerToolUsage `json:"server_tool_use,omitempty"`
 }
 
 func (c *MessageStart) Text() string {
@@ -166,17 +414,14 @@ func (c *MessageStart) Text() string {
 
 func (m *MessageStart) UnmarshalJSON(b []byte) error {
 	type ConcreteResponse struct {
-		ID           string             `json:"id"`
-		Type         string             `json:"type"`
-		Role         string             `json:"role"`
-		Content      []*turnContentText `json:"content"`
-		Model        string             `json:"model"`
-		StopReason   string             `json:"stop_reason"`
-		StopSequence *string            `json:"stop_sequence"`
-		Usage        struct {
-			InputTokens  int `json:"input_tokens"`
-			OutputTokens int `json:"output_tokens"`
-		} `json:"usage"`
+		ID           string            `json:"id"`
+		Type         string            `json:"type"`
+		Role         string            `json:"role"`
+		Content      []json.RawMessage `json:"content"`
+		Model        string            `json:"model"`
+		StopReason   string            `json:"stop_reason"`
+		StopSequence *string           `json:"stop_sequence"`
+		Usage        Usage             `json:"usage"`
 	}
 
 	type hackyBimodalResponse struct {
@@ -197,13 +442,15 @@ func (m *MessageStart) UnmarshalJSON(b []byte) error {
 		c = *hack.ConcreteResponse
 	}
 
+	content, err := unmarshalTurnContents(c.Content)
+	if err != nil {
+		return err
+	}
+
 	m.ID = c.ID
 	m.Type = c.Type
 	m.Role = c.Role
-	m.Content = make([]TurnContent, len(c.Content))
-	for i, c := range c.Content {
-		m.Content[i] = c
-	}
+	m.Content = content
 	m.Model = c.Model
 	m.StopReason = c.StopReason
 	m.StopSequence = c.StopSequence
@@ -227,52 +474,118 @@ func (m *MessageTurn) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	content, err := unmarshalTurnContents(raw.Content)
+	if err != nil {
+		return err
+	}
+
 	m.Role = raw.Role
-	m.Content = make([]TurnContent, len(raw.Content))
+	m.Content = content
+
+	return nil
+}
 
-	for i, rawContent := range raw.Content {
-		var contentType struct {
-			Type string `json:"type"`
+// unmarshalTurnContents decodes a list of raw content blocks into their
+// concrete TurnContent types.
+func unmarshalTurnContents(raw []json.RawMessage) ([]TurnContent, error) {
+	content := make([]TurnContent, len(raw))
+	for i, rawContent := range raw {
+		c, err := unmarshalTurnContent(rawContent)
+		if err != nil {
+			return nil, err
 		}
-		if err := json.Unmarshal(rawContent, &contentType); err != nil {
-			return err
+		content[i] = c
+	}
+	return content, nil
+}
+
+// unmarshalTurnContent decodes a single content block, dispatching on its type field.
+func unmarshalTurnContent(rawContent json.RawMessage) (TurnContent, error) {
+	var contentType struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(rawContent, &contentType); err != nil {
+		return nil, err
+	}
+
+	switch contentType.Type {
+	case TurnText:
+		var textContent turnContentText
+		if err := json.Unmarshal(rawContent, &textContent); err != nil {
+			return nil, err
 		}
+		return &textContent, nil
 
-		switch contentType.Type {
-		case TurnText:
-			var textContent turnContentText
-			if err := json.Unmarshal(rawContent, &textContent); err != nil {
-				return err
-			}
-			m.Content[i] = &textContent
+	case TurnImage:
+		var imageContent turnContentImage
+		if err := json.Unmarshal(rawContent, &imageContent); err != nil {
+			return nil, err
+		}
+		return &imageContent, nil
 
-		case TurnImage:
-			var imageContent turnContentImage
-			if err := json.Unmarshal(rawContent, &imageContent); err != nil {
-				return err
-			}
-			m.Content[i] = &imageContent
+	case TurnToolUse:
+		var toolUse TurnContentToolUse
+		if err := json.Unmarshal(rawContent, &toolUse); err != nil {
+			return nil, err
+		}
+		return &toolUse, nil
 
-		case TurnToolUse:
-			var toolUse TurnContentToolUse
-			if err := json.Unmarshal(rawContent, &toolUse); err != nil {
-				return err
-			}
-			m.Content[i] = &toolUse
+	case TurnToolResult:
+		var toolResult turnContentToolResult
+		if err := json.Unmarshal(rawContent, &toolResult); err != nil {
+			return nil, err
+		}
+		return &toolResult, nil
 
-		case TurnToolResult:
-			var toolResult turnContentToolResult
-			if err := json.Unmarshal(rawContent, &toolResult); err != nil {
-				return err
-			}
-			m.Content[i] = &toolResult
+	case TurnDocument:
+		var document TurnContentDocument
+		if err := json.Unmarshal(rawContent, &document); err != nil {
+			return nil, err
+		}
+		return &document, nil
 
-		default:
-			return fmt.Errorf("unknown content type: %s", contentType.Type)
+	case TurnServerToolUse:
+		var serverToolUse TurnContentServerToolUse
+		if err := json.Unmarshal(rawContent, &serverToolUse); err != nil {
+			return nil, err
 		}
-	}
+		return &serverToolUse, nil
 
-	return nil
+	case TurnWebSearchToolResult:
+		var webSearchResult TurnContentWebSearchToolResult
+		if err := json.Unmarshal(rawContent, &webSearchResult); err != nil {
+			return nil, err
+		}
+		return &webSearchResult, nil
+
+	case TurnCodeExecutionToolResult:
+		var codeExecutionResult TurnContentCodeExecutionToolResult
+		if err := json.Unmarshal(rawContent, &codeExecutionResult); err != nil {
+			return nil, err
+		}
+		return &codeExecutionResult, nil
+
+	case TurnThinking:
+		var thinking TurnContentThinking
+		if err := json.Unmarshal(rawContent, &thinking); err != nil {
+			return nil, err
+		}
+		return &thinking, nil
+
+	case TurnRedactedThinking:
+		var redacted TurnContentRedactedThinking
+		if err := json.Unmarshal(rawContent, &redacted); err != nil {
+			return nil, err
+		}
+		return &redacted, nil
+
+	default:
+		// keep blocks from newer API versions so they survive a round trip
+		return &TurnContentUnknown{
+			Typ: contentType.Type,
+			Raw: append(json.RawMessage(nil), rawContent...),
+		}, nil
+	}
 }
 
 type TurnContent interface {
@@ -281,10 +594,12 @@ type TurnContent interface {
 }
 
 const (
-	TurnText       = "text"
-	TurnImage      = "image"
-	TurnToolUse    = "tool_use"
-	TurnToolResult = "tool_result"
+	TurnText             = "text"
+	TurnImage            = "image"
+	TurnToolUse          = "tool_use"
+	TurnToolResult       = "tool_result"
+	TurnThinking         = "thinking"
+	TurnRedactedThinking = "redacted_thinking"
 )
 
 func TextContent(msg string) TurnContent {
@@ -302,28 +617,90 @@ func (t *turnContentText) TextContent() string {
 	return t.Text
 }
 
+func (t *turnContentText) SetCacheControl(cc *CacheControl) {
+	t.CacheControl = cc
+}
+
 type turnContentText struct {
 	Typ  string `json:"type"`
 	Text string `json:"text"`
+	// Citations are set on responses when citations are enabled on a document.
+	Citations    []Citation    `json:"citations,omitempty"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+func (t *turnContentText) UnmarshalJSON(b []byte) error {
+	type textContent turnContentText
+	var raw struct {
+		textContent
+		Citations []json.RawMessage `json:"citations"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	citations, err := unmarshalCitations(raw.Citations)
+	if err != nil {
+		return err
+	}
+
+	*t = turnContentText(raw.textContent)
+	t.Citations = citations
+	return nil
 }
 
 type turnContentImage struct {
-	Typ    string `json:"type"`
-	Source struct {
-		Type      string `json:"type"`
-		MediaType string `json:"media_type"`
-		Data      []byte `json:"data"`
-	} `json:"source"`
+	Typ          string        `json:"type"`
+	Source       ContentSource `json:"source"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
 }
 
+// ImageContent creates an image content block from inline image data.
 func ImageContent(mediaType string, image []byte) TurnContent {
-	i := turnContentImage{
+	return &turnContentImage{
 		Typ: TurnImage,
+		Source: &Base64Source{
+			MediaType: mediaType,
+			Data:      image,
+		},
+	}
+}
+
+// ImageURLContent creates an image content block that references an image by url.
+func ImageURLContent(url string) TurnContent {
+	return &turnContentImage{
+		Typ:    TurnImage,
+		Source: &URLSource{URL: url},
+	}
+}
+
+// ImageFileContent creates an image content block from a file uploaded
+// with the Files API.
+func ImageFileContent(fileID string) TurnContent {
+	return &turnContentImage{
+		Typ:    TurnImage,
+		Source: &FileSource{FileID: fileID},
+	}
+}
+
+func (t *turnContentImage) UnmarshalJSON(b []byte) error {
+	type imageContent turnContentImage
+	var raw struct {
+		imageContent
+		Source json.RawMessage `json:"source"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
 	}
-	i.Source.Type = "base64"
-	i.Source.MediaType = mediaType
-	i.Source.Data = image
-	return &i
+
+	source, err := unmarshalContentSource(raw.Source)
+	if err != nil {
+		return err
+	}
+
+	*t = turnContentImage(raw.imageContent)
+	t.Source = source
+	return nil
 }
 
 func (t *turnContentImage) Type() string {
@@ -334,11 +711,16 @@ func (t *turnContentImage) TextContent() string {
 	return ""
 }
 
+func (t *turnContentImage) SetCacheControl(cc *CacheControl) {
+	t.CacheControl = cc
+}
+
 type TurnContentToolUse struct {
-	Typ   string      `json:"type"`
-	ID    string      `json:"id"`
-	Name  string      `json:"name"`
-	Input interface{} `json:"input"`
+	Typ          string        `json:"type"`
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Input        interface{}   `json:"input"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
 }
 
 func (t *TurnContentToolUse) Type() string {
@@ -349,6 +731,10 @@ func (t *TurnContentToolUse) TextContent() string {
 	return ""
 }
 
+func (t *TurnContentToolUse) SetCacheControl(cc *CacheControl) {
+	t.CacheControl = cc
+}
+
 func ToolResultContent(toolUseID, content string) TurnContent {
 	return &turnContentToolResult{
 		Typ:         TurnToolResult,
@@ -357,10 +743,88 @@ func ToolResultContent(toolUseID, content string) TurnContent {
 	}
 }
 
+// ToolResultBlocksContent creates a tool result made up of text, image
+// or document content blocks.
+func ToolResultBlocksContent(toolUseID string, content ...TurnContent) TurnContent {
+	return &turnContentToolResult{
+		Typ:       TurnToolResult,
+		ToolUseID: toolUseID,
+		Blocks:    content,
+	}
+}
+
+// ToolErrorContent creates a tool result that tells the model the tool call failed.
+func ToolErrorContent(toolUseID, msg string) TurnContent {
+	return &turnContentToolResult{
+		Typ:         TurnToolResult,
+		ToolUseID:   toolUseID,
+		ToolContent: msg,
+		IsError:     true,
+	}
+}
+
+// IsToolError reports whether c is a tool result with is_error set.
+func IsToolError(c TurnContent) bool {
+	t, ok := c.(*turnContentToolResult)
+	return ok && t.IsError
+}
+
 type turnContentToolResult struct {
-	Typ         string `json:"type"`
-	ToolUseID   string `json:"tool_use_id"`
-	ToolContent string `json:"content"`
+	Typ       string `json:"type"`
+	ToolUseID string `json:"tool_use_id"`
+	// ToolContent is used when the result is a single string.
+	ToolContent string `json:"-"`
+	// Blocks is used when the result is a list of content blocks.
+	// It takes precedence over ToolContent.
+	Blocks       []TurnContent `json:"-"`
+	IsError      bool          `json:"is_error,omitempty"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+func (t *turnContentToolResult) MarshalJSON() ([]byte, error) {
+	type toolResult turnContentToolResult
+	raw := struct {
+		*toolResult
+		Content any `json:"content"`
+	}{
+		toolResult: (*toolResult)(t),
+		Content:    t.ToolContent,
+	}
+	if t.Blocks != nil {
+		raw.Content = t.Blocks
+	}
+	return json.Marshal(raw)
+}
+
+func (t *turnContentToolResult) UnmarshalJSON(b []byte) error {
+	type toolResult turnContentToolResult
+	var raw struct {
+		toolResult
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*t = turnContentToolResult(raw.toolResult)
+
+	// content is either a string or a list of content blocks
+	if len(raw.Content) > 0 && raw.Content[0] == '[' {
+		var rawBlocks []json.RawMessage
+		if err := json.Unmarshal(raw.Content, &rawBlocks); err != nil {
+			return err
+		}
+		blocks, err := unmarshalTurnContents(rawBlocks)
+		if err != nil {
+			return err
+		}
+		t.Blocks = blocks
+	} else if len(raw.Content) > 0 && string(raw.Content) != "null" {
+		if err := json.Unmarshal(raw.Content, &t.ToolContent); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (t *turnContentToolResult) Type() string {
@@ -368,7 +832,71 @@ func (t *turnContentToolResult) Type() string {
 }
 
 func (t *turnContentToolResult) TextContent() string {
-	return t.ToolContent
+	if t.Blocks == nil {
+		return t.ToolContent
+	}
+	text := make([]string, len(t.Blocks))
+	for i, block := range t.Blocks {
+		text[i] = block.TextContent()
+	}
+	return strings.Join(text, "")
+}
+
+func (t *turnContentToolResult) SetCacheControl(cc *CacheControl) {
+	t.CacheControl = cc
+}
+
+// TurnContentThinking is Claude's extended thinking output.
+// When using thinking with tool use, thinking blocks from the last
+// assistant turn must be passed back unmodified, including the Signature.
+type TurnContentThinking struct {
+	Typ       string `json:"type"`
+	Thinking  string `json:"thinking"`
+	Signature string `json:"signature"`
+}
+
+func (t *TurnContentThinking) Type() string {
+	return TurnThinking
+}
+
+func (t *TurnContentThinking) TextContent() string {
+	return ""
+}
+
+// TurnContentRedactedThinking is thinking output that was flagged by
+// safety systems and is returned encrypted. It must be passed back
+// unmodified in multi-turn conversations.
+type TurnContentRedactedThinking struct {
+	Typ  string `json:"type"`
+	Data string `json:"data"`
+}
+
+func (t *TurnContentRedactedThinking) Type() string {
+	return TurnRedactedThinking
+}
+
+func (t *TurnContentRedactedThinking) TextContent() string {
+	return ""
+}
+
+// TurnContentUnknown holds a content block of a type not known to this
+// library. It marshals back to the original JSON so it can be passed
+// back unmodified in multi-turn conversations.
+type TurnContentUnknown struct {
+	Typ string
+	Raw json.RawMessage
+}
+
+func (t *TurnContentUnknown) Type() string {
+	return t.Typ
+}
+
+func (t *TurnContentUnknown) TextContent() string {
+	return ""
+}
+
+func (t *TurnContentUnknown) MarshalJSON() ([]byte, error) {
+	return t.Raw, nil
 }
 
 type MessageEvent struct {
@@ -388,6 +916,37 @@ type ContentBlockStart struct {
 		ID   string `json:"id"`
 	} `json:"content_block"`
 	Index int `json:"index"`
+	// Block is the content block decoded into its concrete TurnContent type.
+	// Block types not known to this library are decoded as *TurnContentUnknown.
+	Block TurnContent `json:"-"`
+}
+
+func (c *ContentBlockStart) UnmarshalJSON(b []byte) error {
+	type contentBlockStart ContentBlockStart
+	var cbs contentBlockStart
+	if err := json.Unmarshal(b, &cbs); err != nil {
+		return err
+	}
+
+	var raw struct {
+		ContentBlock json.RawMessage `json:"content_block"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*c = ContentBlockStart(cbs)
+	if len(raw.ContentBlock) > 0 {
+		// unknown block types decode as TurnContentUnknown, so any
+		// error here is a malformed block of a known type
+		block, err := unmarshalTurnContent(raw.ContentBlock)
+		if err != nil {
+			return fmt.Errorf("decode %s content block: %w", c.ContentBlock.Type, err)
+		}
+		c.Block = block
+	}
+
+	return nil
 }
 
 func (c *ContentBlockStart) Text() string {
@@ -405,11 +964,42 @@ type ContentBlockDelta struct {
 	Delta struct {
 		Text        string `json:"text"`
 		PartialJson string `json:"partial_json"`
+		Thinking    string `json:"thinking"`
+		Signature   string `json:"signature"`
 		Type        string `json:"type"`
+		// Citation is set for citations_delta events.
+		Citation Citation `json:"-"`
 	} `json:"delta"`
 	Index int64 `json:"index"`
 }
 
+func (c *ContentBlockDelta) UnmarshalJSON(b []byte) error {
+	type contentBlockDelta ContentBlockDelta
+	var delta contentBlockDelta
+	if err := json.Unmarshal(b, &delta); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Delta struct {
+			Citation json.RawMessage `json:"citation"`
+		} `json:"delta"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*c = ContentBlockDelta(delta)
+	if len(raw.Delta.Citation) > 0 && string(raw.Delta.Citation) != "null" {
+		citation, err := unmarshalCitation(raw.Delta.Citation)
+		if err != nil {
+			return err
+		}
+		c.Delta.Citation = citation
+	}
+	return nil
+}
+
 func (c *ContentBlockDelta) Text() string {
 	return c.Delta.Text
 }
@@ -429,6 +1019,12 @@ type MessageDelta struct {
 	} `json:"delta"`
 	Usage struct {
 		OutputTokens int64 `json:"output_tokens"`
+		// The following fields are cumulative for the message and are only
+		// sent by newer API versions; they are zero when not present.
+		InputTokens              int64            `json:"input_tokens"`
+		CacheCreationInputTokens int64            `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int64            `json:"cache_read_input_tokens"`
+		ServerToolUse            *ServerToolUsage `json:"server_tool_use"`
 	} `json:"usage"`
 }
 
@@ -473,6 +1069,15 @@ func (c *ClientError) Error() string {
 	return c.error.Error()
 }
 
+func (c *ClientError) Unwrap() error {
+	return c.error
+}
+
 func (c *ClientError) Text() string {
 	return ""
 }
+
+// TokenCount is the result of counting the tokens in a request.
+type TokenCount struct {
+	InputTokens int `json:"input_tokens"`
+}
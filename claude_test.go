@@ -129,12 +129,7 @@ func TestMessageTurnUnmarshalJSON(t *testing.T) {
 				Content: []TurnContent{
 					&turnContentImage{
 						Typ: "image",
-						Source: struct {
-							Type      string `json:"type"`
-							MediaType string `json:"media_type"`
-							Data      []byte `json:"data"`
-						}{
-							Type:      "base64",
+						Source: &Base64Source{
 							MediaType: "image/png",
 							Data:      mustDecodeB64("iVBORw0KGgo="),
 						},
@@ -159,12 +154,7 @@ func TestMessageTurnUnmarshalJSON(t *testing.T) {
 					&turnContentText{Typ: "text", Text: "Here's an image:"},
 					&turnContentImage{
 						Typ: "image",
-						Source: struct {
-							Type      string `json:"type"`
-							MediaType string `json:"media_type"`
-							Data      []byte `json:"data"`
-						}{
-							Type:      "base64",
+						Source: &Base64Source{
 							MediaType: "image/jpeg",
 							Data:      mustDecodeB64("/9j/4AAQSkZJRg=="),
 						},
@@ -174,6 +164,26 @@ func TestMessageTurnUnmarshalJSON(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Image url and file sources",
+			input: `{
+				"role": "user",
+				"content": [
+					{"type": "image", "source": {"type": "url", "url": "https://example.com/cat.jpg"}},
+					{"type": "image", "source": {"type": "file", "file_id": "file_011CNha8iCJcU1wXNR6q4V8w"}},
+					{"type": "document", "source": {"type": "url", "url": "https://example.com/paper.pdf"}}
+				]
+			}`,
+			expected: MessageTurn{
+				Role: "user",
+				Content: []TurnContent{
+					ImageURLContent("https://example.com/cat.jpg"),
+					ImageFileContent("file_011CNha8iCJcU1wXNR6q4V8w"),
+					URLDocumentContent("https://example.com/paper.pdf"),
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "Tool Use content",
 			input: `{
@@ -215,16 +225,114 @@ func TestMessageTurnUnmarshalJSON(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Tool result with content blocks",
+			input: `{
+				"role": "user",
+				"content": [
+					{"type": "tool_result", "tool_use_id": "tool1", "is_error": true, "content": [
+						{"type": "text", "text": "Screenshot of the failure:"},
+						{"type": "image", "source": {"type": "url", "url": "https://example.com/screenshot.png"}}
+					]}
+				]
+			}`,
+			expected: MessageTurn{
+				Role: "user",
+				Content: []TurnContent{
+					&turnContentToolResult{
+						Typ:       "tool_result",
+						ToolUseID: "tool1",
+						IsError:   true,
+						Blocks: []TurnContent{
+							TextContent("Screenshot of the failure:"),
+							ImageURLContent("https://example.com/screenshot.png"),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Server tool results",
+			input: `{
+				"role": "assistant",
+				"content": [
+					{"type": "server_tool_use", "id": "srvtoolu_1", "name": "code_execution", "input": {"code": "print(1+1)"}},
+					{"type": "code_execution_tool_result", "tool_use_id": "srvtoolu_1", "content": {"type": "code_execution_result", "stdout": "2\n", "stderr": "", "return_code": 0, "content": []}},
+					{"type": "web_search_tool_result", "tool_use_id": "srvtoolu_2", "content": {"type": "web_search_tool_result_error", "error_code": "max_uses_exceeded"}}
+				]
+			}`,
+			expected: MessageTurn{
+				Role: "assistant",
+				Content: []TurnContent{
+					&TurnContentServerToolUse{
+						Typ:   "server_tool_use",
+						ID:    "srvtoolu_1",
+						Name:  "code_execution",
+						Input: map[string]any{"code": "print(1+1)"},
+					},
+					&TurnContentCodeExecutionToolResult{
+						Typ:       "code_execution_tool_result",
+						ToolUseID: "srvtoolu_1",
+						Result: &CodeExecutionResult{
+							Stdout:  "2\n",
+							Content: []CodeExecutionOutput{},
+						},
+					},
+					&TurnContentWebSearchToolResult{
+						Typ:       "web_search_tool_result",
+						ToolUseID: "srvtoolu_2",
+						Error:     &ServerToolError{ErrorCode: "max_uses_exceeded"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Thinking content",
+			input: `{
+				"role": "assistant",
+				"content": [
+					{"type": "thinking", "thinking": "Let me analyze this step by step...", "signature": "WaUjzkypQ2mUEVM36O2TxuC06KN8xyfbJwyem2dw3URve/op91XWHOEBLLqIOMfFG/UvLEczmEsUjavL...."},
+					{"type": "redacted_thinking", "data": "EmwKAhgBEgy3va3pzix/LafPsn4aDFIT2Xlxh0L5L8rLVyIwxtE3rAFBa8cr3qpPkNRj2YfWXGmKDxH4mPnZ5sQ7vB9URj2pLmN3kF8/dW5hR7xJ0aP1oLs9yTcMnKVf2wRpEGjH9XZaBt4UvDcPrQ..."},
+					{"type": "text", "text": "Based on my analysis..."}
+				]
+			}`,
+			expected: MessageTurn{
+				Role: "assistant",
+				Content: []TurnContent{
+					&TurnContentThinking{
+						Typ:       "thinking",
+						Thinking:  "Let me analyze this step by step...",
+						Signature: "WaUjzkypQ2mUEVM36O2TxuC06KN8xyfbJwyem2dw3URve/op91XWHOEBLLqIOMfFG/UvLEczmEsUjavL....",
+					},
+					&TurnContentRedactedThinking{
+						Typ:  "redacted_thinking",
+						Data: "EmwKAhgBEgy3va3pzix/LafPsn4aDFIT2Xlxh0L5L8rLVyIwxtE3rAFBa8cr3qpPkNRj2YfWXGmKDxH4mPnZ5sQ7vB9URj2pLmN3kF8/dW5hR7xJ0aP1oLs9yTcMnKVf2wRpEGjH9XZaBt4UvDcPrQ...",
+					},
+					&turnContentText{Typ: "text", Text: "Based on my analysis..."},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "Unknown content type",
 			input: `{
 				"role": "user",
 				"content": [
-					{"type": "unknown", "data": "This should fail"}
+					{"type": "unknown", "data": "kept as is"}
 				]
 			}`,
-			expected: MessageTurn{},
-			wantErr:  true,
+			expected: MessageTurn{
+				Role: "user",
+				Content: []TurnContent{
+					&TurnContentUnknown{
+						Typ: "unknown",
+						Raw: json.RawMessage(`{"type": "unknown", "data": "kept as is"}`),
+					},
+				},
+			},
+			wantErr: false,
 		},
 	}
 
@@ -248,5 +356,413 @@ func TestMessageTurnUnmarshalJSON(t *testing.T) {
 }
 
 func TestUmarshalMessageStart(t *testing.T) {
+	respJSON := `{
+  "id": "msg_01Aq9w938a90dw8q",
+  "model": "claude-3-7-sonnet-20250219",
+  "stop_reason": "tool_use",
+  "role": "assistant",
+  "type": "message",
+  "content": [
+    {
+      "type": "text",
+      "text": "I'll check the weather."
+    },
+    {
+      "type": "tool_use",
+      "id": "toolu_01A09q90qw90lq917835lq9",
+      "name": "get_weather",
+      "input": {"location": "San Francisco, CA", "unit": "celsius"}
+    }
+  ],
+  "usage": {
+    "input_tokens": 10,
+    "output_tokens": 25
+  }
+}`
+
+	var mr MessageStart
+	err := json.Unmarshal([]byte(respJSON), &mr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := MessageStart{
+		ID:         "msg_01Aq9w938a90dw8q",
+		Model:      "claude-3-7-sonnet-20250219",
+		Role:       "assistant",
+		StopReason: "tool_use",
+		Type:       "message",
+		Content: []TurnContent{
+			TextContent("I'll check the weather."),
+			&TurnContentToolUse{
+				Typ:   "tool_use",
+				ID:    "toolu_01A09q90qw90lq917835lq9",
+				Name:  "get_weather",
+				Input: map[string]interface{}{"location": "San Francisco, CA", "unit": "celsius"},
+			},
+		},
+	}
+	expect.Usage.InputTokens = 10
+	expect.Usage.OutputTokens = 25
+
+	if diff := cmp.Diff(expect, mr); diff != "" {
+		t.Fatalf("UnmarshalJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMarshalPromptCaching(t *testing.T) {
+	req := MessageRequest{
+		Model:     Claude3Dot7SonnetLatest,
+		MaxTokens: 1024,
+		SystemBlocks: []SystemBlock{
+			{Text: "You are an AI assistant tasked with analyzing literary works."},
+			{Text: "[the entire contents of Pride and Prejudice]", CacheControl: EphemeralCache(CacheTTL1Hour)},
+		},
+		Tools: []Tool{
+			{
+				Name:         "get_weather",
+				InputSchema:  map[string]any{"type": "object"},
+				CacheControl: EphemeralCache(""),
+			},
+		},
+		Messages: []MessageTurn{
+			{
+				Role: RoleUser,
+				Content: []TurnContent{
+					WithCacheControl(TextContent("Analyze the major themes."), EphemeralCache("")),
+				},
+			},
+		},
+	}
+
+	got, err := json.Marshal(&req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `{"model":"claude-3-7-sonnet-latest","messages":[{"role":"user","content":[{"type":"text","text":"Analyze the major themes.","cache_control":{"type":"ephemeral"}}]}],"max_tokens":1024,"tools":[{"name":"get_weather","input_schema":{"type":"object"},"cache_control":{"type":"ephemeral"}}],"system":[{"type":"text","text":"You are an AI assistant tasked with analyzing literary works."},{"type":"text","text":"[the entire contents of Pride and Prejudice]","cache_control":{"type":"ephemeral","ttl":"1h"}}]}`
+	if diff := cmp.Diff(expect, string(got)); diff != "" {
+		t.Fatalf("MarshalJSON() mismatch (-want +got):\n%s", diff)
+	}
+
+	req.System = "conflicting system prompt"
+	if _, err := json.Marshal(&req); err == nil {
+		t.Fatal("expected error when both System and SystemBlocks are set")
+	}
+
+	var msg MessageStart
+	err = json.Unmarshal([]byte(`{"type":"message","content":[],"usage":{"input_tokens":21,"cache_creation_input_tokens":188086,"cache_read_input_tokens":0,"output_tokens":393}}`), &msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectUsage := Usage{InputTokens: 21, OutputTokens: 393, CacheCreationInputTokens: 188086}
+	if diff := cmp.Diff(expectUsage, msg.Usage); diff != "" {
+		t.Fatalf("Usage mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDocumentContent(t *testing.T) {
+	pdf := PDFDocumentContent([]byte("%PDF-1.4"))
+	pdf.Title = "Report"
+	pdf.EnableCitations()
+
+	text := TextDocumentContent("The grass is green. The sky is blue.")
+	text.Context = "Trustworthy document."
+
+	custom := CustomDocumentContent(TextContent("First chunk"), TextContent("Second chunk"))
+
+	turn := MessageTurn{
+		Role:    RoleUser,
+		Content: []TurnContent{pdf, text, custom, TextContent("Summarize these documents.")},
+	}
 
+	got, err := json.Marshal(turn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `{"role":"user","content":[` +
+		`{"type":"document","source":{"type":"base64","media_type":"application/pdf","data":"JVBERi0xLjQ="},"title":"Report","citations":{"enabled":true}},` +
+		`{"type":"document","source":{"type":"text","media_type":"text/plain","data":"The grass is green. The sky is blue."},"context":"Trustworthy document."},` +
+		`{"type":"document","source":{"type":"content","content":[{"type":"text","text":"First chunk"},{"type":"text","text":"Second chunk"}]}},` +
+		`{"type":"text","text":"Summarize these documents."}]}`
+	if diff := cmp.Diff(expect, string(got)); diff != "" {
+		t.Fatalf("MarshalJSON() mismatch (-want +got):\n%s", diff)
+	}
+
+	var roundTrip MessageTurn
+	if err := json.Unmarshal(got, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(turn, roundTrip); diff != "" {
+		t.Fatalf("UnmarshalJSON() mismatch (-want +got):\n%s", diff)
+	}
+
+	// sources of unknown types are kept as sent
+	unknown := `{"role":"user","content":[{"type":"document","source":{"type":"bogus","ref":"abc"}},{"type":"image","source":{"type":"bogus"}}]}`
+	if err := json.Unmarshal([]byte(unknown), &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	expectSources := []ContentSource{
+		&UnknownSource{Typ: "bogus", Raw: json.RawMessage(`{"type":"bogus","ref":"abc"}`)},
+		&UnknownSource{Typ: "bogus", Raw: json.RawMessage(`{"type":"bogus"}`)},
+	}
+	gotSources := []ContentSource{
+		roundTrip.Content[0].(*TurnContentDocument).Source,
+		roundTrip.Content[1].(*turnContentImage).Source,
+	}
+	if diff := cmp.Diff(expectSources, gotSources); diff != "" {
+		t.Fatalf("unknown source mismatch (-want +got):\n%s", diff)
+	}
+	got, err = json.Marshal(roundTrip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(unknown, string(got)); diff != "" {
+		t.Fatalf("MarshalJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMarshalContentSources(t *testing.T) {
+	content := []TurnContent{
+		ImageContent("image/png", []byte{0x89, 'P', 'N', 'G'}),
+		ImageURLContent("https://example.com/cat.jpg"),
+		ImageFileContent("file_abc"),
+		FileDocumentContent("file_def"),
+	}
+
+	got, err := json.Marshal(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `[` +
+		`{"type":"image","source":{"type":"base64","media_type":"image/png","data":"iVBORw=="}},` +
+		`{"type":"image","source":{"type":"url","url":"https://example.com/cat.jpg"}},` +
+		`{"type":"image","source":{"type":"file","file_id":"file_abc"}},` +
+		`{"type":"document","source":{"type":"file","file_id":"file_def"}}]`
+	if diff := cmp.Diff(expect, string(got)); diff != "" {
+		t.Fatalf("MarshalJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMarshalToolResult(t *testing.T) {
+	content := []TurnContent{
+		ToolResultContent("toolu_1", "15 degrees"),
+		ToolErrorContent("toolu_2", "location not found"),
+		ToolResultBlocksContent("toolu_3", TextContent("chart:"), ImageFileContent("file_abc")),
+	}
+
+	got, err := json.Marshal(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `[` +
+		`{"type":"tool_result","tool_use_id":"toolu_1","content":"15 degrees"},` +
+		`{"type":"tool_result","tool_use_id":"toolu_2","is_error":true,"content":"location not found"},` +
+		`{"type":"tool_result","tool_use_id":"toolu_3","content":[{"type":"text","text":"chart:"},{"type":"image","source":{"type":"file","file_id":"file_abc"}}]}]`
+	if diff := cmp.Diff(expect, string(got)); diff != "" {
+		t.Fatalf("MarshalJSON() mismatch (-want +got):\n%s", diff)
+	}
+
+	var roundTrip []json.RawMessage
+	if err := json.Unmarshal(got, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := unmarshalTurnContents(roundTrip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(content, decoded); diff != "" {
+		t.Fatalf("UnmarshalJSON() mismatch (-want +got):\n%s", diff)
+	}
+	if decoded[2].TextContent() != "chart:" {
+		t.Fatalf("TextContent() = %q", decoded[2].TextContent())
+	}
+}
+
+func TestMarshalToolChoice(t *testing.T) {
+	tools := []Tool{{Name: "get_weather", InputSchema: map[string]any{"type": "object"}}}
+
+	tests := []struct {
+		name     string
+		choice   *ToolChoice
+		thinking *ThinkingConfig
+		expected string
+		wantErr  bool
+	}{
+		{name: "auto", choice: AutoToolChoice(), expected: `{"type":"auto"}`},
+		{name: "any", choice: &ToolChoice{Type: ToolChoiceAny, DisableParallelToolUse: true}, expected: `{"type":"any","disable_parallel_tool_use":true}`},
+		{name: "tool", choice: NamedToolChoice("get_weather"), expected: `{"type":"tool","name":"get_weather"}`},
+		{name: "none", choice: NoneToolChoice(), expected: `{"type":"none"}`},
+		{name: "legacy tool", choice: &ToolChoice{Tool: "get_weather"}, expected: `{"type":"tool","name":"get_weather"}`},
+		{name: "legacy any", choice: &ToolChoice{Any: true}, expected: `{"type":"any"}`},
+		{name: "missing type", choice: &ToolChoice{}, wantErr: true},
+		{name: "unknown type", choice: &ToolChoice{Type: "sometimes"}, wantErr: true},
+		{name: "tool without name", choice: &ToolChoice{Type: ToolChoiceTool}, wantErr: true},
+		{name: "name without tool type", choice: &ToolChoice{Type: ToolChoiceAuto, Name: "get_weather"}, wantErr: true},
+		{name: "unknown tool", choice: NamedToolChoice("get_time"), wantErr: true},
+		{name: "none with disable parallel", choice: &ToolChoice{Type: ToolChoiceNone, DisableParallelToolUse: true}, wantErr: true},
+		{name: "conflicting legacy fields", choice: &ToolChoice{Any: true, Auto: true}, wantErr: true},
+		{name: "legacy conflicts with type", choice: &ToolChoice{Type: ToolChoiceNone, Any: true}, wantErr: true},
+		{name: "any with thinking", choice: AnyToolChoice(), thinking: ThinkingEnabled(2048), wantErr: true},
+		{name: "auto with thinking", choice: AutoToolChoice(), thinking: ThinkingEnabled(2048), expected: `{"type":"auto"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := MessageRequest{
+				Model:      Claude3Dot7SonnetLatest,
+				MaxTokens:  4096,
+				Tools:      tools,
+				ToolChoice: tt.choice,
+				Thinking:   tt.thinking,
+			}
+			got, err := json.Marshal(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var decoded struct {
+				ToolChoice json.RawMessage `json:"tool_choice"`
+			}
+			if err := json.Unmarshal(got, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expected, string(decoded.ToolChoice)); diff != "" {
+				t.Fatalf("tool_choice mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeBuiltinToolInput(t *testing.T) {
+	var toolUse TurnContentToolUse
+	err := json.Unmarshal([]byte(`{"type":"tool_use","id":"toolu_1","name":"str_replace_based_edit_tool","input":{"command":"insert","path":"main.go","insert_line":0,"new_str":"package main\n"}}`), &toolUse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var input TextEditorInput
+	if err := toolUse.DecodeInput(&input); err != nil {
+		t.Fatal(err)
+	}
+	zero := 0
+	expect := TextEditorInput{
+		Command:    TextEditorInsert,
+		Path:       "main.go",
+		NewStr:     "package main\n",
+		InsertLine: &zero,
+	}
+	if diff := cmp.Diff(expect, input); diff != "" {
+		t.Fatalf("DecodeInput() mismatch (-want +got):\n%s", diff)
+	}
+
+	toolUse.Input = map[string]any{"command": "delete", "path": "main.go"}
+	if err := toolUse.DecodeInput(&input); err == nil {
+		t.Fatal("expected error for unknown text editor command")
+	}
+
+	toolUse.Input = map[string]any{"action": "left_click", "coordinate": []any{100, 200}}
+	var computer ComputerInput
+	if err := toolUse.DecodeInput(&computer); err != nil {
+		t.Fatal(err)
+	}
+	if computer.Action != "left_click" || len(computer.Coordinate) != 2 || computer.Coordinate[1] != 200 {
+		t.Fatalf("unexpected computer input: %+v", computer)
+	}
+}
+
+func TestUnknownContentRoundTrip(t *testing.T) {
+	respJSON := `{"id":"msg_01","type":"message","role":"assistant","model":"claude-sonnet-4-5-20250929","content":[{"type":"text","text":"hi"},{"type":"future_block","payload":{"a":[1,2]}}],"stop_reason":"end_turn","stop_sequence":null,"usage":{"input_tokens":1,"output_tokens":2}}`
+
+	var msg MessageStart
+	if err := json.Unmarshal([]byte(respJSON), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Content) != 2 || msg.Content[1].Type() != "future_block" {
+		t.Fatalf("unexpected content: %+v", msg.Content)
+	}
+
+	turn := MessageTurn{Role: RoleAssistant, Content: msg.Content}
+	b, err := json.Marshal(turn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"role":"assistant","content":[{"type":"text","text":"hi"},{"type":"future_block","payload":{"a":[1,2]}}]}`
+	if string(b) != expect {
+		t.Fatalf("round trip mismatch:\ngot  %s\nwant %s", b, expect)
+	}
+}
+
+func TestContentBlockStartUnmarshal(t *testing.T) {
+	var start ContentBlockStart
+	err := json.Unmarshal([]byte(`{"type":"content_block_start","index":0,"content_block":{"type":"future_block","x":1}}`), &start)
+	if err != nil {
+		t.Fatalf("unknown block type should decode: %v", err)
+	}
+	if _, ok := start.Block.(*TurnContentUnknown); !ok {
+		t.Fatalf("expected TurnContentUnknown, got %T", start.Block)
+	}
+
+	for _, raw := range []string{
+		`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_01","name":"get_weather","input":{},"cache_control":"bad"}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":["not a string"]}}`,
+	} {
+		var start ContentBlockStart
+		if err := json.Unmarshal([]byte(raw), &start); err == nil {
+			t.Errorf("expected error decoding malformed block %s", raw)
+		}
+	}
+}
+
+func TestRequiredBetas(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    MessageRequest
+		expect []string
+	}{
+		{
+			name: "no betas",
+			req: MessageRequest{
+				Messages: []MessageTurn{{Role: RoleUser, Content: []TurnContent{TextContent("hi")}}},
+			},
+		},
+		{
+			name: "file document and computer tool",
+			req: MessageRequest{
+				Tools:    []Tool{ComputerTool(ToolTypeComputer20250124, 1024, 768)},
+				Messages: []MessageTurn{{Role: RoleUser, Content: []TurnContent{FileDocumentContent("file_01")}}},
+			},
+			expect: []string{"computer-use-2025-01-24", FilesBeta},
+		},
+		{
+			name: "file image in tool result",
+			req: MessageRequest{
+				Messages: []MessageTurn{{Role: RoleUser, Content: []TurnContent{
+					ToolResultBlocksContent("toolu_01", ImageFileContent("file_02")),
+				}}},
+			},
+			expect: []string{FilesBeta},
+		},
+		{
+			name: "already enabled",
+			req: MessageRequest{
+				AnthropicBeta: []string{FilesBeta},
+				Messages:      []MessageTurn{{Role: RoleUser, Content: []TurnContent{ImageFileContent("file_02")}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expect, tt.req.RequiredBetas()); diff != "" {
+				t.Errorf("RequiredBetas() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
 }
@@ -2,6 +2,9 @@ package clientiface
 
 import (
 	"context"
+	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/psanford/claude"
 )
@@ -10,6 +13,55 @@ type Client interface {
 	Message(ctx context.Context, req *claude.MessageRequest, options ...Option) (claude.MessageResponse, error)
 }
 
+// TokenCounter is implemented by clients that can count the input tokens
+// of a request without sending it to the model. Not every provider supports
+// this, so type-assert a Client to check:
+//
+//	if tc, ok := client.(clientiface.TokenCounter); ok {
+//		count, err := tc.CountTokens(ctx, req)
+//	}
+type TokenCounter interface {
+	CountTokens(ctx context.Context, req *claude.MessageRequest, options ...Option) (*claude.TokenCount, error)
+}
+
+// Option configures a single call to Client.Message.
+// Use the With* functions in this package to construct options,
+// or implement Set to create your own.
 type Option interface {
-	set(*Client)
+	Set(*RequestOptions)
+}
+
+// RequestOptions holds the per-request settings collected from a list of Options.
+// Client implementations should call NewRequestOptions to build it.
+type RequestOptions struct {
+	// Header contains extra HTTP headers to send with the request.
+	// These replace any default header with the same name.
+	Header http.Header
+	// Betas are anthropic-beta feature flags to enable for this request.
+	Betas []string
+	// Timeout bounds the total time of the request, including any
+	// retries and reading a streaming response. Zero means no timeout.
+	Timeout time.Duration
+	// DebugLogger overrides the client's debug logger for this request.
+	DebugLogger *slog.Logger
+	// RequestID is a caller supplied identifier sent in the RequestIDHeader header.
+	RequestID string
+	// ResponseHook is called with the raw http response once the response
+	// headers have been received. The hook must not read or close the body.
+	ResponseHook func(*http.Response)
+	// RetryPolicy overrides the client's retry policy for this request.
+	RetryPolicy *RetryPolicy
+}
+
+// RequestIDHeader is the header used to send RequestOptions.RequestID.
+const RequestIDHeader = "X-Client-Request-Id"
+
+func NewRequestOptions(opts ...Option) *RequestOptions {
+	ro := &RequestOptions{
+		Header: make(http.Header),
+	}
+	for _, opt := range opts {
+		opt.Set(ro)
+	}
+	return ro
 }
@@ -0,0 +1,103 @@
+package clientiface
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type headerOption struct {
+	key   string
+	value string
+}
+
+func (o *headerOption) Set(ro *RequestOptions) {
+	ro.Header.Add(o.key, o.value)
+}
+
+// WithHeader adds an extra HTTP header to the request.
+func WithHeader(key, value string) Option {
+	return &headerOption{
+		key:   key,
+		value: value,
+	}
+}
+
+type betaOption struct {
+	betas []string
+}
+
+func (o *betaOption) Set(ro *RequestOptions) {
+	ro.Betas = append(ro.Betas, o.betas...)
+}
+
+// WithBeta enables anthropic-beta feature flags for the request.
+func WithBeta(betas ...string) Option {
+	return &betaOption{
+		betas: betas,
+	}
+}
+
+type timeoutOption struct {
+	d time.Duration
+}
+
+func (o *timeoutOption) Set(ro *RequestOptions) {
+	ro.Timeout = o.d
+}
+
+// WithTimeout sets a timeout for the whole request, including
+// reading a streaming response.
+func WithTimeout(d time.Duration) Option {
+	return &timeoutOption{
+		d: d,
+	}
+}
+
+type debugLoggerOption struct {
+	l *slog.Logger
+}
+
+func (o *debugLoggerOption) Set(ro *RequestOptions) {
+	ro.DebugLogger = o.l
+}
+
+// WithDebugLogger overrides the client's debug logger for the request.
+func WithDebugLogger(l *slog.Logger) Option {
+	return &debugLoggerOption{
+		l: l,
+	}
+}
+
+type requestIDOption struct {
+	id string
+}
+
+func (o *requestIDOption) Set(ro *RequestOptions) {
+	ro.RequestID = o.id
+}
+
+// WithRequestID sends id in the RequestIDHeader header.
+func WithRequestID(id string) Option {
+	return &requestIDOption{
+		id: id,
+	}
+}
+
+type responseHookOption struct {
+	hook func(*http.Response)
+}
+
+func (o *responseHookOption) Set(ro *RequestOptions) {
+	ro.ResponseHook = o.hook
+}
+
+// WithResponseHook registers a function that is called with the raw
+// http response once the response headers are received. This can be used
+// to capture response metadata such as status code and headers.
+// The hook must not read or close the response body.
+func WithResponseHook(hook func(*http.Response)) Option {
+	return &responseHookOption{
+		hook: hook,
+	}
+}
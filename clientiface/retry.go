@@ -0,0 +1,97 @@
+package clientiface
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls automatic retries of failed requests.
+//
+// A request is only retried before any response event has been delivered
+// to the caller, so retries are never visible in a response stream.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// A value of 1 or less disables retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, including a retry-after
+	// value sent by the server.
+	MaxBackoff time.Duration
+	// Multiplier is the backoff growth factor between attempts. Defaults to 2.
+	Multiplier float64
+	// Jitter randomizes each backoff by up to this fraction of its value (0.0-1.0).
+	Jitter float64
+	// RetryableErrorTypes lists the API error types that should be retried
+	// (e.g. "rate_limit_error", "overloaded_error").
+	RetryableErrorTypes []string
+}
+
+// DefaultRetryPolicy returns a policy that retries rate limit, overloaded
+// and internal api errors up to 3 times in total. Transport errors such as
+// a reset connection are classified as api errors.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.25,
+		RetryableErrorTypes: []string{
+			"rate_limit_error",
+			"overloaded_error",
+			"api_error",
+		},
+	}
+}
+
+// Retryable reports whether errorType is in RetryableErrorTypes.
+func (p *RetryPolicy) Retryable(errorType string) bool {
+	for _, t := range p.RetryableErrorTypes {
+		if t == errorType {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay before the given retry. retry starts at 1.
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	mult := p.Multiplier
+	if mult <= 0 {
+		mult = 2
+	}
+
+	d := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		d *= mult
+		if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(d)
+}
+
+type retryPolicyOption struct {
+	p *RetryPolicy
+}
+
+func (o *retryPolicyOption) Set(ro *RequestOptions) {
+	ro.RetryPolicy = o.p
+}
+
+// WithRetryPolicy overrides the client's retry policy for the request.
+// Pass a policy with MaxAttempts of 1 to disable retries.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return &retryPolicyOption{
+		p: p,
+	}
+}
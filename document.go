@@ -0,0 +1,117 @@
+package claude
+
+import (
+	"encoding/json"
+)
+
+const TurnDocument = "document"
+
+// TurnContentDocument is a document content block.
+// Use PDFDocumentContent, URLDocumentContent, FileDocumentContent,
+// TextDocumentContent or CustomDocumentContent to create one,
+// then set the optional fields as needed.
+// See https://docs.anthropic.com/en/docs/build-with-claude/pdf-support for details.
+type TurnContentDocument struct {
+	Typ    string        `json:"type"`
+	Source ContentSource `json:"source"`
+	// Optional document title. This is passed to the model and used in citations.
+	Title string `json:"title,omitempty"`
+	// Optional context about the document. This is passed to the model but not used in citations.
+	Context string `json:"context,omitempty"`
+	// Set to enable citations for this document.
+	Citations    *CitationsConfig `json:"citations,omitempty"`
+	CacheControl *CacheControl    `json:"cache_control,omitempty"`
+}
+
+// CitationsConfig enables or disables citations for a document.
+type CitationsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// PDFDocumentContent creates a document content block from a PDF file.
+func PDFDocumentContent(pdf []byte) *TurnContentDocument {
+	return &TurnContentDocument{
+		Typ: TurnDocument,
+		Source: &Base64Source{
+			MediaType: "application/pdf",
+			Data:      pdf,
+		},
+	}
+}
+
+// URLDocumentContent creates a document content block from a PDF at url.
+func URLDocumentContent(url string) *TurnContentDocument {
+	return &TurnContentDocument{
+		Typ:    TurnDocument,
+		Source: &URLSource{URL: url},
+	}
+}
+
+// FileDocumentContent creates a document content block from a file
+// uploaded with the Files API.
+func FileDocumentContent(fileID string) *TurnContentDocument {
+	return &TurnContentDocument{
+		Typ:    TurnDocument,
+		Source: &FileSource{FileID: fileID},
+	}
+}
+
+// TextDocumentContent creates a document content block from plain text.
+func TextDocumentContent(text string) *TurnContentDocument {
+	return &TurnContentDocument{
+		Typ: TurnDocument,
+		Source: &TextSource{
+			MediaType: "text/plain",
+			Data:      text,
+		},
+	}
+}
+
+// CustomDocumentContent creates a document from a list of text or image
+// content blocks. When citations are enabled, each block is a citable unit.
+func CustomDocumentContent(content ...TurnContent) *TurnContentDocument {
+	return &TurnContentDocument{
+		Typ: TurnDocument,
+		Source: &ContentBlockSource{
+			Content: content,
+		},
+	}
+}
+
+// EnableCitations turns on citations for the document and returns it.
+func (t *TurnContentDocument) EnableCitations() *TurnContentDocument {
+	t.Citations = &CitationsConfig{Enabled: true}
+	return t
+}
+
+func (t *TurnContentDocument) Type() string {
+	return TurnDocument
+}
+
+func (t *TurnContentDocument) TextContent() string {
+	return ""
+}
+
+func (t *TurnContentDocument) SetCacheControl(cc *CacheControl) {
+	t.CacheControl = cc
+}
+
+func (t *TurnContentDocument) UnmarshalJSON(b []byte) error {
+	type turnContentDocument TurnContentDocument
+	var raw struct {
+		turnContentDocument
+		Source json.RawMessage `json:"source"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	source, err := unmarshalContentSource(raw.Source)
+	if err != nil {
+		return err
+	}
+
+	*t = TurnContentDocument(raw.turnContentDocument)
+	t.Source = source
+	return nil
+}
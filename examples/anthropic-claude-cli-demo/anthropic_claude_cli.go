@@ -71,6 +71,7 @@ func completeResponse(ctx context.Context, client clientiface.Client, prompt str
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer respMeta.Close()
 	resp := <-respMeta.Responses()
 
 	if resp.Type != "message" {
@@ -111,6 +112,7 @@ func streamingResponse(ctx context.Context, client clientiface.Client, prompt st
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer respMeta.Close()
 	for resp := range respMeta.Responses() {
 		if *debug {
 			log.Printf("response: %s", resp.Type)
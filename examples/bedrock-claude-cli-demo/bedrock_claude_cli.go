@@ -76,6 +76,7 @@ func completeResponse(ctx context.Context, client clientiface.Client, prompt str
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer respMeta.Close()
 	resp := <-respMeta.Responses()
 
 	if resp.Type != "message" {
@@ -116,6 +117,7 @@ func streamingResponse(ctx context.Context, client clientiface.Client, prompt st
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer respMeta.Close()
 	for resp := range respMeta.Responses() {
 		if *debug {
 			log.Printf("response: %s", resp.Type)
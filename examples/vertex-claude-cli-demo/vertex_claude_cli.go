@@ -86,6 +86,7 @@ func completeResponse(ctx context.Context, client clientiface.Client, prompt str
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer respMeta.Close()
 	resp := <-respMeta.Responses()
 
 	if resp.Type != "message" {
@@ -126,6 +127,7 @@ func streamingResponse(ctx context.Context, client clientiface.Client, prompt st
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer respMeta.Close()
 	for resp := range respMeta.Responses() {
 		if *debug {
 			log.Printf("response: %s", resp.Type)
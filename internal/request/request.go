@@ -1,9 +1,31 @@
 package request
 
-import "github.com/psanford/claude"
+import (
+	"net/http"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+)
 
 func SetDefaults(req *claude.MessageRequest) {
 	if req.MaxTokens < 1 {
 		req.MaxTokens = 4096
 	}
 }
+
+// ApplyHeaders adds the per-request headers, beta flags and request ID
+// from ro to h. Headers in ro.Header replace existing values in h.
+func ApplyHeaders(h http.Header, ro *clientiface.RequestOptions) {
+	for _, beta := range ro.Betas {
+		h.Add("anthropic-beta", beta)
+	}
+	if ro.RequestID != "" {
+		h.Set(clientiface.RequestIDHeader, ro.RequestID)
+	}
+	for k, vals := range ro.Header {
+		h.Del(k)
+		for _, v := range vals {
+			h.Add(k, v)
+		}
+	}
+}
@@ -3,52 +3,60 @@ package responseparser
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"mime"
+	"net"
 	"net/http"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/psanford/claude"
 )
 
-func HandleResponse(ctx context.Context, resp *http.Response, debugLogger *slog.Logger) (claude.MessageResponse, error) {
+// HandleResponse converts an http response from the messages API into a claude.MessageResponse.
+// elapsed is the time between sending the request and receiving the response headers.
+func HandleResponse(ctx context.Context, resp *http.Response, elapsed time.Duration, debugLogger *slog.Logger) (claude.MessageResponse, error) {
 	if resp.StatusCode != 200 {
-		r := io.LimitReader(resp.Body, 1<<13)
-		body, err := io.ReadAll(r)
-		if err != nil {
-			return nil, fmt.Errorf("%d error response: %s", resp.StatusCode, body)
-		}
-		var ew errWrapper
-		err = json.Unmarshal(body, &ew)
-		if err != nil {
-			return nil, fmt.Errorf("%d error response: %s", resp.StatusCode, body)
-		}
-		return nil, ew.Error
+		return nil, NewAPIError(resp)
 	}
 
 	contentType := resp.Header.Get("Content-Type")
 	mediatype, _, _ := mime.ParseMediaType(contentType)
+	metadata := claude.ResponseMetadata{
+		StatusCode:     resp.StatusCode,
+		RequestID:      resp.Header.Get("request-id"),
+		RateLimit:      claude.RateLimitFromHeader(resp.Header),
+		ProcessingTime: elapsed,
+		Header:         resp.Header,
+	}
+
 	if mediatype == "text/event-stream" {
-		return handleSSE(ctx, resp, debugLogger)
+		return handleSSE(ctx, resp, metadata, debugLogger)
 	} else if mediatype == "application/json" {
-		return handleNonStreamingResponse(ctx, resp, debugLogger)
+		return handleNonStreamingResponse(ctx, resp, metadata, debugLogger)
 	} else {
 		return nil, fmt.Errorf("unexpected response content-type: %s", contentType)
 	}
 }
 
-func handleSSE(ctx context.Context, resp *http.Response, debugLogger *slog.Logger) (claude.MessageResponse, error) {
+func handleSSE(ctx context.Context, resp *http.Response, metadata claude.ResponseMetadata, debugLogger *slog.Logger) (claude.MessageResponse, error) {
+	// canceled when the stream finishes so decodeSSE never outlives it
+	ctx, cancel := context.WithCancel(ctx)
 	eventsCh := decodeSSE(ctx, resp.Body)
 
-	ch := make(chan claude.MessageEvent)
+	stream := NewStream(metadata, resp.Body.Close)
 	meta := messageResponse{
-		responses:    ch,
+		Stream:       stream,
 		httpResponse: resp,
 	}
 
 	go func() {
-		defer close(ch)
+		defer stream.Finish()
+		defer cancel()
 
 		for evt := range eventsCh {
 			if debugLogger != nil && debugLogger.Enabled(ctx, slog.LevelDebug) {
@@ -57,15 +65,7 @@ func handleSSE(ctx context.Context, resp *http.Response, debugLogger *slog.Logge
 
 			var msg claude.MessageEvent
 			if evt.Error != nil {
-				msg = claude.MessageEvent{
-					Type: "_client_error",
-					Data: claude.NewClientError(evt.Error),
-				}
-				select {
-				case ch <- msg:
-				case <-ctx.Done():
-				}
-
+				stream.SendFinal(ctx, clientError(evt.Error))
 				return
 			}
 
@@ -91,37 +91,27 @@ func handleSSE(ctx context.Context, resp *http.Response, debugLogger *slog.Logge
 			case "error":
 				innerMsg = &claude.ClaudeError{}
 			default:
-				msg = claude.MessageEvent{
-					Type: "_client_error",
-					Data: claude.NewClientError(fmt.Errorf("unknown event type: %s", evt.Name)),
-				}
-				select {
-				case ch <- msg:
-				case <-ctx.Done():
-				}
-
+				stream.SendFinal(ctx, clientError(fmt.Errorf("unknown event type: %s", evt.Name)))
 				return
 			}
 
 			err := json.Unmarshal([]byte(evt.Data), innerMsg)
 			if err != nil {
-				msg = claude.MessageEvent{
-					Type: "_client_error",
-					Data: claude.NewClientError(fmt.Errorf("parse event err: %w", err)),
-				}
-				select {
-				case ch <- msg:
-				case <-ctx.Done():
-				}
-
+				stream.SendFinal(ctx, clientError(fmt.Errorf("parse event err: %w", err)))
 				return
 			}
 
 			msg.Data = innerMsg
-			select {
-			case ch <- msg:
-			case <-ctx.Done():
-				return
+			if !stream.Send(ctx, msg) {
+				break
+			}
+		}
+
+		select {
+		case <-stream.Closed():
+		default:
+			if err := ctx.Err(); err != nil {
+				stream.SendFinal(ctx, clientError(err))
 			}
 		}
 	}()
@@ -129,7 +119,9 @@ func handleSSE(ctx context.Context, resp *http.Response, debugLogger *slog.Logge
 	return &meta, nil
 }
 
-func handleNonStreamingResponse(ctx context.Context, resp *http.Response, debugLogger *slog.Logger) (claude.MessageResponse, error) {
+func handleNonStreamingResponse(ctx context.Context, resp *http.Response, metadata claude.ResponseMetadata, debugLogger *slog.Logger) (claude.MessageResponse, error) {
+	defer resp.Body.Close()
+
 	d := json.NewDecoder(resp.Body)
 	var msg claude.MessageStart
 	err := d.Decode(&msg)
@@ -140,9 +132,9 @@ func handleNonStreamingResponse(ctx context.Context, resp *http.Response, debugL
 		debugLogger.Debug("response", "message_start", msg)
 	}
 
-	ch := make(chan claude.MessageEvent)
+	stream := NewStream(metadata, nil)
 	meta := messageResponse{
-		responses:    ch,
+		Stream:       stream,
 		httpResponse: resp,
 	}
 
@@ -151,40 +143,104 @@ func handleNonStreamingResponse(ctx context.Context, resp *http.Response, debugL
 		Data: &msg,
 	}
 	go func() {
-		select {
-		case ch <- evt:
-		case <-ctx.Done():
-		}
-
-		close(ch)
+		defer stream.Finish()
+		stream.Send(ctx, evt)
 	}()
 
 	return &meta, nil
 }
 
 type messageResponse struct {
-	responses    <-chan claude.MessageEvent
+	*Stream
 	httpResponse *http.Response
 }
 
-func (m *messageResponse) Responses() <-chan claude.MessageEvent {
-	return m.responses
-}
-
 func (m *messageResponse) HTTPResponse() *http.Response {
 	return m.httpResponse
 }
 
-type Error struct {
-	Type    string `json:"type"`
-	Message string `json:"message"`
-}
+// NewAPIError builds a *claude.APIError from a non-success http response.
+// It reads and closes the response body.
+func NewAPIError(resp *http.Response) *claude.APIError {
+	defer resp.Body.Close()
+	r := io.LimitReader(resp.Body, 1<<13)
+	body, _ := io.ReadAll(r)
+
+	apiErr := &claude.APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("request-id"),
+		RetryAfter: ParseRetryAfter(resp.Header.Get("retry-after")),
+		RateLimit:  claude.RateLimitFromHeader(resp.Header),
+	}
+
+	var ew errWrapper
+	err := json.Unmarshal(body, &ew)
+	if err != nil || ew.Error.Message == "" {
+		apiErr.Message = fmt.Sprintf("error response: %s", body)
+	} else {
+		apiErr.Type = ew.Error.Type
+		apiErr.Message = ew.Error.Message
+	}
+	if apiErr.Type == "" {
+		apiErr.Type = claude.ErrorTypeForStatus(resp.StatusCode)
+	}
 
-func (e Error) Error() string {
-	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+	return apiErr
 }
 
 type errWrapper struct {
 	Type  string `json:"type"`
-	Error Error  `json:"error"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ParseRetryAfter parses a retry-after header value, which may be
+// either a number of seconds or an http date. Values in the past are
+// returned as 0.
+func ParseRetryAfter(v string) time.Duration {
+	var d time.Duration
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		d = time.Duration(secs * float64(time.Second))
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = time.Until(t)
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// ClassifyError returns the API error type and retry-after delay for errors
+// produced by HandleResponse or received as stream error events.
+// Transport errors such as a reset connection or an EOF before the
+// response headers are classified as api_error so they can be retried;
+// context cancellation is never retried.
+func ClassifyError(err error) (string, time.Duration) {
+	var apiErr *claude.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Type, apiErr.RetryAfter
+	}
+	if typ := claude.ErrorType(err); typ != "" {
+		return typ, 0
+	}
+	if isTransportError(err) {
+		return claude.ErrorTypeAPI, 0
+	}
+	return "", 0
+}
+
+func isTransportError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
@@ -74,7 +74,7 @@ func decodeSSE(ctx context.Context, r io.Reader) chan sseEvent {
 		}
 
 		if err := scanner.Err(); err != nil {
-			event.Error = fmt.Errorf("scan error: %s", err)
+			event.Error = fmt.Errorf("scan error: %w", err)
 			select {
 			case ch <- event:
 			case <-ctx.Done():
@@ -0,0 +1,162 @@
+package responseparser
+
+import (
+	"context"
+	"sync"
+
+	"github.com/psanford/claude"
+)
+
+// Stream implements the event delivery and lifecycle parts of
+// claude.MessageResponse for a single producer goroutine.
+//
+// The producer sends events with Send, delivers a final error event with
+// SendFinal and must call Finish when it is done.
+type Stream struct {
+	ch       chan claude.MessageEvent
+	metadata claude.ResponseMetadata
+	release  func() error
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	finished  chan struct{}
+
+	mu         sync.Mutex
+	err        error
+	releaseErr error
+}
+
+// NewStream returns a Stream. release is called once, when the producer
+// finishes or the consumer calls Close, to free the underlying connection.
+// It may be nil.
+func NewStream(metadata claude.ResponseMetadata, release func() error) *Stream {
+	if release == nil {
+		release = func() error { return nil }
+	}
+	return &Stream{
+		// buffered so SendFinal can deliver the final event after ctx is done
+		ch:       make(chan claude.MessageEvent, 1),
+		metadata: metadata,
+		release:  sync.OnceValue(release),
+		closed:   make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+}
+
+func (s *Stream) Responses() <-chan claude.MessageEvent {
+	return s.ch
+}
+
+func (s *Stream) Metadata() claude.ResponseMetadata {
+	return s.metadata
+}
+
+func (s *Stream) Close() error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		select {
+		case <-s.finished:
+		default:
+			if s.err == nil {
+				s.err = claude.ErrResponseClosed
+			}
+		}
+		s.mu.Unlock()
+
+		close(s.closed)
+		// releasing the connection unblocks a producer waiting on a read
+		s.releaseErr = s.release()
+	})
+
+	<-s.finished
+	// discard anything still buffered so no events are read after Close
+	for range s.ch {
+	}
+	return s.releaseErr
+}
+
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Closed is closed when the consumer calls Close.
+func (s *Stream) Closed() <-chan struct{} {
+	return s.closed
+}
+
+// Send delivers evt to the consumer. It returns false if the consumer
+// closed the response or ctx is done, in which case the producer should stop.
+func (s *Stream) Send(ctx context.Context, evt claude.MessageEvent) bool {
+	s.recordErr(evt)
+	select {
+	case s.ch <- evt:
+		return true
+	case <-s.closed:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// SendFinal delivers evt as the last event. It blocks until the consumer
+// reads it or closes the response. If ctx is done the consumer may have
+// stopped reading, so an unread event in the buffer is replaced by evt
+// to make sure the stream still ends with it.
+func (s *Stream) SendFinal(ctx context.Context, evt claude.MessageEvent) {
+	s.recordErr(evt)
+
+	select {
+	case s.ch <- evt:
+		return
+	case <-s.closed:
+		return
+	case <-ctx.Done():
+	}
+
+	select {
+	case s.ch <- evt:
+		return
+	default:
+	}
+
+	select {
+	case <-s.ch:
+	default:
+	}
+	s.ch <- evt
+}
+
+// Finish closes the Responses channel and releases the connection.
+func (s *Stream) Finish() {
+	s.release()
+	close(s.ch)
+	close(s.finished)
+}
+
+func (s *Stream) recordErr(evt claude.MessageEvent) {
+	var err error
+	switch e := evt.Data.(type) {
+	case *claude.ClaudeError:
+		err = e
+	case *claude.ClientError:
+		err = e
+	default:
+		return
+	}
+
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+}
+
+// clientError returns a _client_error event for err.
+func clientError(err error) claude.MessageEvent {
+	return claude.MessageEvent{
+		Type: "_client_error",
+		Data: claude.NewClientError(err),
+	}
+}
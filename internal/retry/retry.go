@@ -0,0 +1,199 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+)
+
+// Classifier returns the API error type for err (e.g. "overloaded_error")
+// and the delay requested by the server via retry-after, if any.
+// It returns an empty type for errors that should never be retried.
+type Classifier func(err error) (errorType string, retryAfter time.Duration)
+
+// Attempt performs a single request.
+type Attempt func(ctx context.Context) (claude.MessageResponse, error)
+
+// Do runs attempt, retrying according to policy. A nil policy disables retries.
+//
+// A non-zero timeout bounds all attempts, the backoff between them and
+// reading the returned response; its context is released once the
+// response has ended.
+//
+// For successful responses Do waits for the first event. If that event is
+// a retryable error event the request is retried; otherwise the event is
+// replayed to the caller so no event is lost or delivered twice.
+func Do(ctx context.Context, timeout time.Duration, policy *clientiface.RetryPolicy, classify Classifier, attempt Attempt) (claude.MessageResponse, error) {
+	if timeout <= 0 {
+		return do(ctx, policy, classify, attempt)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	resp, err := do(ctx, policy, classify, attempt)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return replay(ctx, resp, nil, cancel), nil
+}
+
+func do(ctx context.Context, policy *clientiface.RetryPolicy, classify Classifier, attempt Attempt) (claude.MessageResponse, error) {
+	for try := 1; ; try++ {
+		canRetry := policy != nil && try < policy.MaxAttempts
+
+		resp, err := attempt(ctx)
+		if err == nil && !canRetry {
+			return resp, nil
+		}
+
+		if err == nil {
+			var first *claude.MessageEvent
+			resp, first = peek(ctx, resp)
+			if first == nil {
+				return resp, nil
+			}
+			apiErr, ok := first.Data.(*claude.ClaudeError)
+			if !ok {
+				return resp, nil
+			}
+			if typ, _ := classify(apiErr); !policy.Retryable(typ) {
+				return resp, nil
+			}
+			resp.Close()
+			err = apiErr
+		} else if !canRetry {
+			return nil, err
+		}
+
+		typ, retryAfter := classify(err)
+		if !policy.Retryable(typ) {
+			return nil, err
+		}
+
+		delay := policy.Backoff(try)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+			delay = policy.MaxBackoff
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// peek reads the first event from resp and returns a response that
+// replays it followed by the remaining events.
+func peek(ctx context.Context, resp claude.MessageResponse) (claude.MessageResponse, *claude.MessageEvent) {
+	src := resp.Responses()
+
+	var first claude.MessageEvent
+	select {
+	case evt, ok := <-src:
+		if !ok {
+			return resp, nil
+		}
+		first = evt
+	case <-ctx.Done():
+		return resp, nil
+	}
+
+	return replay(ctx, resp, &first, nil), &first
+}
+
+// replay returns a response that delivers first, if non-nil, followed by
+// the remaining events of resp. onDone, if non-nil, is called once resp
+// has no more events.
+func replay(ctx context.Context, resp claude.MessageResponse, first *claude.MessageEvent, onDone func()) *replayResponse {
+	src := resp.Responses()
+
+	// buffered so the final event can be delivered after ctx is done; see send
+	ch := make(chan claude.MessageEvent, 1)
+	closed := make(chan struct{})
+	done := make(chan struct{})
+	// forward until src is closed rather than stopping on ctx: the
+	// producer watches ctx itself and ends with a terminal error event
+	// that must reach the caller.
+	go func() {
+		if onDone != nil {
+			defer onDone()
+		}
+		defer close(done)
+		defer close(ch)
+
+		send := func(evt claude.MessageEvent) bool {
+			select {
+			case ch <- evt:
+				return true
+			case <-closed:
+				return false
+			case <-ctx.Done():
+			}
+
+			// the caller may have stopped reading; replace any unread
+			// event so the stream still ends with the producer's final
+			// event without blocking.
+			select {
+			case ch <- evt:
+				return true
+			default:
+			}
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- evt
+			return true
+		}
+
+		if first != nil && !send(*first) {
+			return
+		}
+		for evt := range src {
+			if !send(evt) {
+				return
+			}
+		}
+	}()
+
+	return &replayResponse{
+		MessageResponse: resp,
+		responses:       ch,
+		closed:          closed,
+		done:            done,
+	}
+}
+
+type replayResponse struct {
+	claude.MessageResponse
+	responses <-chan claude.MessageEvent
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (r *replayResponse) Responses() <-chan claude.MessageEvent {
+	return r.responses
+}
+
+func (r *replayResponse) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+	})
+	err := r.MessageResponse.Close()
+	// the Responses channel must be closed and empty by the time Close returns
+	<-r.done
+	for range r.responses {
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package retry
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+	"github.com/psanford/claude/internal/responseparser"
+)
+
+// streamUntilCancel returns a response that sends message_start and then
+// ends with a _client_error once ctx is done, like the real clients.
+func streamUntilCancel(ctx context.Context) claude.MessageResponse {
+	s := responseparser.NewStream(claude.ResponseMetadata{}, nil)
+	go func() {
+		defer s.Finish()
+		if !s.Send(ctx, claude.MessageEvent{Type: "message_start", Data: &claude.MessageStart{Type: "message_start"}}) {
+			return
+		}
+		s.Send(ctx, claude.MessageEvent{Type: "ping", Data: &claude.MessagePing{}})
+		<-ctx.Done()
+		s.SendFinal(ctx, claude.MessageEvent{Type: "_client_error", Data: claude.NewClientError(ctx.Err())})
+	}()
+	return s
+}
+
+func replayGoroutines() int {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	return strings.Count(string(buf), "retry.replay.func")
+}
+
+func TestReplayCancelWithoutClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resp, err := Do(ctx, 0, clientiface.DefaultRetryPolicy(), responseparser.ClassifyError, func(ctx context.Context) (claude.MessageResponse, error) {
+		return streamUntilCancel(ctx), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if evt := <-resp.Responses(); evt.Type != "message_start" {
+		t.Fatalf("unexpected first event: %+v", evt)
+	}
+
+	// stop reading without calling Close
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for replayGoroutines() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("replay goroutine did not exit after ctx was cancelled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var last claude.MessageEvent
+	for evt := range resp.Responses() {
+		last = evt
+	}
+	if last.Type != "_client_error" {
+		t.Fatalf("expected the stream to end with _client_error, got %+v", last)
+	}
+}
@@ -0,0 +1,24 @@
+package claude
+
+import (
+	"net/http"
+	"time"
+)
+
+// ResponseMetadata holds provider-neutral information about the http
+// response to a request.
+type ResponseMetadata struct {
+	// StatusCode is the http status code of the response.
+	StatusCode int
+	// RequestID is the provider's identifier for the request.
+	RequestID string
+	// RateLimit holds the anthropic-ratelimit-* headers. Only the Anthropic
+	// API returns these; for other providers it is the zero value.
+	RateLimit RateLimit
+	// ProcessingTime is the time the provider reported spending on the
+	// request. If the provider doesn't report it, this is the time between
+	// sending the request and receiving the response headers.
+	ProcessingTime time.Duration
+	// Header is the raw http response header.
+	Header http.Header
+}
@@ -1,11 +1,31 @@
 package claude
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
 const (
+	ClaudeOpus4Dot5Latest   = "claude-opus-4-5"
+	ClaudeSonnet4Dot5Latest = "claude-sonnet-4-5"
+	ClaudeHaiku4Dot5Latest  = "claude-haiku-4-5"
+	ClaudeOpus4Dot1Latest   = "claude-opus-4-1"
+	ClaudeOpus4Latest       = "claude-opus-4-0"
+	ClaudeSonnet4Latest     = "claude-sonnet-4-0"
 	Claude3Dot7SonnetLatest = "claude-3-7-sonnet-latest"
 	Claude3Dot5SonnetLatest = "claude-3-5-sonnet-latest"
 	Claude3Dot5HaikuLatest  = "claude-3-5-haiku-latest"
 	Claude3OpusLatest       = "claude-3-opus-latest"
 
+	ClaudeOpus4Dot5       = "claude-opus-4-5-20251101"
+	ClaudeSonnet4Dot5     = "claude-sonnet-4-5-20250929"
+	ClaudeHaiku4Dot5      = "claude-haiku-4-5-20251001"
+	ClaudeOpus4Dot1       = "claude-opus-4-1-20250805"
+	ClaudeOpus4           = "claude-opus-4-20250514"
+	ClaudeSonnet4         = "claude-sonnet-4-20250514"
 	Claude3Dot7Sonnet2502 = "claude-3-7-sonnet-20250219"
 	Claude3Dot5Sonnet2410 = "claude-3-5-sonnet-20241022"
 	Claude3Dot5Sonnet     = "claude-3-5-sonnet-20240620"
@@ -18,25 +38,397 @@ const (
 	Claude1Dot2Instant    = "claude-instant-1.2"
 )
 
+// ModelInfo describes a model and its capabilities.
+type ModelInfo struct {
+	// ID is the canonical, dated Anthropic model ID.
+	ID          string
+	DisplayName string
+	// Aliases are alternate Anthropic IDs that resolve to this model, e.g. "claude-sonnet-4-5".
+	// The first alias is the preferred one.
+	Aliases []string
+	// BedrockID is the Amazon Bedrock model ID, or empty if the model is not available on Bedrock.
+	BedrockID string
+	// BedrockGeographies lists the geographies, e.g. "us" or "eu", that have a Bedrock
+	// cross-region inference profile for the model.
+	BedrockGeographies []string
+	// VertexID is the Google Vertex AI model ID, or empty if the model is not available on Vertex.
+	VertexID string
+	// Released is the date the model was released.
+	Released time.Time
+
+	// ContextWindow is the maximum number of input and output tokens.
+	ContextWindow int
+	// MaxOutputTokens is the maximum max_tokens value without betas.
+	MaxOutputTokens int
+
+	Vision           bool
+	ExtendedThinking bool
+	ToolUse          bool
+
+	// DeprecatedOn is the date the model was deprecated, or zero if it has not been.
+	DeprecatedOn time.Time
+	// RetiredOn is the date the model stopped being available, or zero if it has not been scheduled.
+	RetiredOn time.Time
+}
+
+// Deprecated reports whether the model has been deprecated.
+func (m *ModelInfo) Deprecated() bool {
+	return !m.DeprecatedOn.IsZero() && !time.Now().Before(m.DeprecatedOn)
+}
+
+// Retired reports whether the model is no longer available.
+func (m *ModelInfo) Retired() bool {
+	return !m.RetiredOn.IsZero() && !time.Now().Before(m.RetiredOn)
+}
+
+// clone returns a copy of m that does not share slices with the registry.
+func (m ModelInfo) clone() ModelInfo {
+	m.Aliases = append([]string(nil), m.Aliases...)
+	m.BedrockGeographies = append([]string(nil), m.BedrockGeographies...)
+	return m
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+var (
+	registryMu sync.RWMutex
+	// registry is the list of known models. See RegisterModel.
+	registry = builtinModels()
+)
+
+func builtinModels() []ModelInfo {
+	return []ModelInfo{
+		{
+			ID:                 ClaudeOpus4Dot5,
+			DisplayName:        "Claude Opus 4.5",
+			Aliases:            []string{ClaudeOpus4Dot5Latest},
+			BedrockID:          "anthropic.claude-opus-4-5-20251101-v1:0",
+			BedrockGeographies: []string{"us", "eu", "global"},
+			VertexID:           "claude-opus-4-5@20251101",
+			Released:           date(2025, time.November, 24),
+			ContextWindow:      200000,
+			MaxOutputTokens:    64000,
+			Vision:             true,
+			ExtendedThinking:   true,
+			ToolUse:            true,
+		},
+		{
+			ID:                 ClaudeHaiku4Dot5,
+			DisplayName:        "Claude Haiku 4.5",
+			Aliases:            []string{ClaudeHaiku4Dot5Latest},
+			BedrockID:          "anthropic.claude-haiku-4-5-20251001-v1:0",
+			BedrockGeographies: []string{"us", "eu", "apac", "global"},
+			VertexID:           "claude-haiku-4-5@20251001",
+			Released:           date(2025, time.October, 15),
+			ContextWindow:      200000,
+			MaxOutputTokens:    64000,
+			Vision:             true,
+			ExtendedThinking:   true,
+			ToolUse:            true,
+		},
+		{
+			ID:                 ClaudeSonnet4Dot5,
+			DisplayName:        "Claude Sonnet 4.5",
+			Aliases:            []string{ClaudeSonnet4Dot5Latest},
+			BedrockID:          "anthropic.claude-sonnet-4-5-20250929-v1:0",
+			BedrockGeographies: []string{"us", "eu", "apac", "us-gov", "global"},
+			VertexID:           "claude-sonnet-4-5@20250929",
+			Released:           date(2025, time.September, 29),
+			ContextWindow:      200000,
+			MaxOutputTokens:    64000,
+			Vision:             true,
+			ExtendedThinking:   true,
+			ToolUse:            true,
+		},
+		{
+			ID:                 ClaudeOpus4Dot1,
+			DisplayName:        "Claude Opus 4.1",
+			Aliases:            []string{ClaudeOpus4Dot1Latest},
+			BedrockID:          "anthropic.claude-opus-4-1-20250805-v1:0",
+			BedrockGeographies: []string{"us"},
+			VertexID:           "claude-opus-4-1@20250805",
+			Released:           date(2025, time.August, 5),
+			ContextWindow:      200000,
+			MaxOutputTokens:    32000,
+			Vision:             true,
+			ExtendedThinking:   true,
+			ToolUse:            true,
+		},
+		{
+			ID:                 ClaudeOpus4,
+			DisplayName:        "Claude Opus 4",
+			Aliases:            []string{ClaudeOpus4Latest},
+			BedrockID:          "anthropic.claude-opus-4-20250514-v1:0",
+			BedrockGeographies: []string{"us"},
+			VertexID:           "claude-opus-4@20250514",
+			Released:           date(2025, time.May, 22),
+			ContextWindow:      200000,
+			MaxOutputTokens:    32000,
+			Vision:             true,
+			ExtendedThinking:   true,
+			ToolUse:            true,
+		},
+		{
+			ID:                 ClaudeSonnet4,
+			DisplayName:        "Claude Sonnet 4",
+			Aliases:            []string{ClaudeSonnet4Latest},
+			BedrockID:          "anthropic.claude-sonnet-4-20250514-v1:0",
+			BedrockGeographies: []string{"us", "eu", "apac", "global"},
+			VertexID:           "claude-sonnet-4@20250514",
+			Released:           date(2025, time.May, 22),
+			ContextWindow:      200000,
+			MaxOutputTokens:    64000,
+			Vision:             true,
+			ExtendedThinking:   true,
+			ToolUse:            true,
+		},
+		{
+			ID:                 Claude3Dot7Sonnet2502,
+			DisplayName:        "Claude Sonnet 3.7",
+			Aliases:            []string{Claude3Dot7SonnetLatest},
+			BedrockID:          "anthropic.claude-3-7-sonnet-20250219-v1:0",
+			BedrockGeographies: []string{"us", "eu", "apac", "us-gov"},
+			VertexID:           "claude-3-7-sonnet@20250219",
+			Released:           date(2025, time.February, 24),
+			ContextWindow:      200000,
+			MaxOutputTokens:    64000,
+			Vision:             true,
+			ExtendedThinking:   true,
+			ToolUse:            true,
+			DeprecatedOn:       date(2025, time.October, 28),
+			RetiredOn:          date(2026, time.February, 19),
+		},
+		{
+			ID:                 Claude3Dot5Sonnet2410,
+			DisplayName:        "Claude Sonnet 3.5 (New)",
+			Aliases:            []string{Claude3Dot5SonnetLatest},
+			BedrockID:          "anthropic.claude-3-5-sonnet-20241022-v2:0",
+			BedrockGeographies: []string{"us", "apac"},
+			VertexID:           "claude-3-5-sonnet-v2@20241022",
+			Released:           date(2024, time.October, 22),
+			ContextWindow:      200000,
+			MaxOutputTokens:    8192,
+			Vision:             true,
+			ToolUse:            true,
+			DeprecatedOn:       date(2025, time.August, 13),
+			RetiredOn:          date(2025, time.October, 22),
+		},
+		{
+			ID:                 Claude3Dot5Haiku,
+			DisplayName:        "Claude Haiku 3.5",
+			Aliases:            []string{Claude3Dot5HaikuLatest},
+			BedrockID:          "anthropic.claude-3-5-haiku-20241022-v1:0",
+			BedrockGeographies: []string{"us"},
+			VertexID:           "claude-3-5-haiku@20241022",
+			Released:           date(2024, time.October, 22),
+			ContextWindow:      200000,
+			MaxOutputTokens:    8192,
+			ToolUse:            true,
+		},
+		{
+			ID:                 Claude3Dot5Sonnet,
+			DisplayName:        "Claude Sonnet 3.5 (Old)",
+			BedrockID:          "anthropic.claude-3-5-sonnet-20240620-v1:0",
+			BedrockGeographies: []string{"us", "eu", "apac", "us-gov"},
+			VertexID:           "claude-3-5-sonnet@20240620",
+			Released:           date(2024, time.June, 20),
+			ContextWindow:      200000,
+			MaxOutputTokens:    8192,
+			Vision:             true,
+			ToolUse:            true,
+			DeprecatedOn:       date(2025, time.August, 13),
+			RetiredOn:          date(2025, time.October, 22),
+		},
+		{
+			ID:                 Claude3Haiku,
+			DisplayName:        "Claude Haiku 3",
+			BedrockID:          "anthropic.claude-3-haiku-20240307-v1:0",
+			BedrockGeographies: []string{"us", "eu", "apac", "us-gov"},
+			VertexID:           "claude-3-haiku@20240307",
+			Released:           date(2024, time.March, 7),
+			ContextWindow:      200000,
+			MaxOutputTokens:    4096,
+			Vision:             true,
+			ToolUse:            true,
+		},
+		{
+			ID:                 Claude3Opus,
+			DisplayName:        "Claude Opus 3",
+			Aliases:            []string{Claude3OpusLatest},
+			BedrockID:          "anthropic.claude-3-opus-20240229-v1:0",
+			BedrockGeographies: []string{"us"},
+			VertexID:           "claude-3-opus@20240229",
+			Released:           date(2024, time.February, 29),
+			ContextWindow:      200000,
+			MaxOutputTokens:    4096,
+			Vision:             true,
+			ToolUse:            true,
+			DeprecatedOn:       date(2025, time.June, 30),
+			RetiredOn:          date(2026, time.January, 5),
+		},
+		{
+			ID:                 Claude3Sonnet,
+			DisplayName:        "Claude Sonnet 3",
+			BedrockID:          "anthropic.claude-3-sonnet-20240229-v1:0",
+			BedrockGeographies: []string{"us", "eu", "apac"},
+			VertexID:           "claude-3-sonnet@20240229",
+			Released:           date(2024, time.February, 29),
+			ContextWindow:      200000,
+			MaxOutputTokens:    4096,
+			Vision:             true,
+			ToolUse:            true,
+			DeprecatedOn:       date(2025, time.January, 21),
+			RetiredOn:          date(2025, time.July, 21),
+		},
+		{
+			ID:              Claude2Dot1,
+			DisplayName:     "Claude 2.1",
+			BedrockID:       "anthropic.claude-v2:1",
+			Released:        date(2023, time.November, 21),
+			ContextWindow:   200000,
+			MaxOutputTokens: 4096,
+			DeprecatedOn:    date(2025, time.January, 21),
+			RetiredOn:       date(2025, time.July, 21),
+		},
+		{
+			ID:              Clause2Dot0,
+			DisplayName:     "Claude 2.0",
+			BedrockID:       "anthropic.claude-v2",
+			Released:        date(2023, time.July, 11),
+			ContextWindow:   100000,
+			MaxOutputTokens: 4096,
+			DeprecatedOn:    date(2025, time.January, 21),
+			RetiredOn:       date(2025, time.July, 21),
+		},
+		{
+			ID:              Claude1Dot2Instant,
+			DisplayName:     "Claude Instant 1.2",
+			BedrockID:       "anthropic.claude-instant-v1",
+			Released:        date(2023, time.August, 9),
+			ContextWindow:   100000,
+			MaxOutputTokens: 4096,
+			DeprecatedOn:    date(2024, time.September, 4),
+			RetiredOn:       date(2024, time.November, 6),
+		},
+	}
+}
+
+// RegisterModel adds a model to the registry, or replaces the registered
+// model with the same ID. It returns an error if the ID is empty or if any of
+// the model's identifiers are already used by a different model.
+func RegisterModel(m ModelInfo) error {
+	if m.ID == "" {
+		return errors.New("register model: ID is required")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	replace := -1
+	for i, existing := range registry {
+		if existing.ID == m.ID {
+			replace = i
+			continue
+		}
+		for _, id := range m.identifiers() {
+			if existing.matches(id) {
+				return fmt.Errorf("register model %s: %s is already used by %s", m.ID, id, existing.ID)
+			}
+		}
+	}
+
+	m = m.clone()
+	if replace >= 0 {
+		registry[replace] = m
+	} else {
+		registry = append(registry, m)
+	}
+	return nil
+}
+
+// identifiers returns every ID that refers to m.
+func (m *ModelInfo) identifiers() []string {
+	ids := append([]string{m.ID}, m.Aliases...)
+	if m.BedrockID != "" {
+		ids = append(ids, m.BedrockID)
+	}
+	if m.VertexID != "" {
+		ids = append(ids, m.VertexID)
+	}
+	return ids
+}
+
+func (m *ModelInfo) matches(id string) bool {
+	for _, candidate := range m.identifiers() {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// LookupModel returns the model with the given Anthropic ID, alias,
+// Bedrock ID or Vertex ID.
+func LookupModel(id string) (ModelInfo, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, m := range registry {
+		if m.matches(id) {
+			return m.clone(), true
+		}
+	}
+	return ModelInfo{}, false
+}
+
+// FindModels returns the known models for which match returns true, newest first.
+// Retired models are excluded.
+func FindModels(match func(m ModelInfo) bool) []ModelInfo {
+	registryMu.RLock()
+	all := make([]ModelInfo, len(registry))
+	for i, m := range registry {
+		all[i] = m.clone()
+	}
+	registryMu.RUnlock()
+
+	var found []ModelInfo
+	for _, m := range all {
+		if m.Retired() {
+			continue
+		}
+		if match == nil || match(m) {
+			found = append(found, m)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Released.After(found[j].Released)
+	})
+	return found
+}
+
+// Models returns the IDs of all known models, including retired ones.
 func Models() []string {
-	return []string{
-		Claude3Dot7Sonnet2502,
-		Claude3Dot5Sonnet2410,
-		Claude3Opus,
-		Claude3Dot5Haiku,
-		Claude3Dot5Sonnet,
-		Claude3Sonnet,
-		Claude3Haiku,
-		Claude2Dot1,
-		Clause2Dot0,
-		Claude1Dot2Instant,
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	ids := make([]string, len(registry))
+	for i, m := range registry {
+		ids[i] = m.ID
 	}
+	return ids
 }
 
+// CurrentModels returns the preferred ID of each model that has not been deprecated, newest first.
 func CurrentModels() []string {
-	return []string{
-		Claude3Dot7SonnetLatest,
-		Claude3Dot5Haiku,
-		Claude3OpusLatest,
+	var ids []string
+	for _, m := range FindModels(func(m ModelInfo) bool { return !m.Deprecated() }) {
+		if len(m.Aliases) > 0 {
+			ids = append(ids, m.Aliases[0])
+		} else {
+			ids = append(ids, m.ID)
+		}
 	}
+	return ids
 }
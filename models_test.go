@@ -0,0 +1,84 @@
+package claude
+
+import "testing"
+
+func TestModelRegistry(t *testing.T) {
+	m, ok := LookupModel(ClaudeSonnet4Dot5Latest)
+	if !ok {
+		t.Fatalf("alias %s not found", ClaudeSonnet4Dot5Latest)
+	}
+	if m.ID != ClaudeSonnet4Dot5 || !m.ExtendedThinking || m.MaxOutputTokens != 64000 {
+		t.Fatalf("unexpected model: %+v", m)
+	}
+
+	m.Aliases[0] = "modified"
+	if m, _ := LookupModel(ClaudeSonnet4Dot5); m.Aliases[0] != ClaudeSonnet4Dot5Latest {
+		t.Fatal("LookupModel result shares memory with the registry")
+	}
+
+	if _, ok := LookupModel("claude-nonexistent"); ok {
+		t.Fatal("expected unknown model to not be found")
+	}
+
+	for _, m := range FindModels(func(m ModelInfo) bool { return !m.Vision }) {
+		if m.Vision {
+			t.Errorf("FindModels returned non-matching model %s", m.ID)
+		}
+		if m.Retired() {
+			t.Errorf("FindModels returned retired model %s", m.ID)
+		}
+	}
+
+	thinking := FindModels(func(m ModelInfo) bool { return m.ExtendedThinking })
+	for i := 1; i < len(thinking); i++ {
+		if thinking[i].Released.After(thinking[i-1].Released) {
+			t.Fatalf("FindModels not sorted newest first: %s before %s", thinking[i-1].ID, thinking[i].ID)
+		}
+	}
+
+	for _, id := range CurrentModels() {
+		m, ok := LookupModel(id)
+		if !ok || m.Deprecated() {
+			t.Errorf("CurrentModels returned unknown or deprecated model %s", id)
+		}
+	}
+}
+
+func TestRegisterModel(t *testing.T) {
+	custom := ModelInfo{
+		ID:              "claude-test-model-20990101",
+		DisplayName:     "Claude Test",
+		Aliases:         []string{"claude-test-model"},
+		BedrockID:       "anthropic.claude-test-model-20990101-v1:0",
+		VertexID:        "claude-test-model@20990101",
+		ContextWindow:   200000,
+		MaxOutputTokens: 8192,
+	}
+	if err := RegisterModel(custom); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{custom.ID, "claude-test-model", custom.BedrockID, custom.VertexID} {
+		m, ok := LookupModel(id)
+		if !ok || m.ID != custom.ID {
+			t.Errorf("LookupModel(%q) = %+v, %v", id, m, ok)
+		}
+	}
+
+	// re-registering the same ID replaces the model
+	custom.MaxOutputTokens = 16384
+	if err := RegisterModel(custom); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := LookupModel(custom.ID); m.MaxOutputTokens != 16384 {
+		t.Errorf("expected replaced model, got %+v", m)
+	}
+
+	conflict := ModelInfo{ID: "claude-other-20990101", Aliases: []string{ClaudeSonnet4Dot5Latest}}
+	if err := RegisterModel(conflict); err == nil {
+		t.Error("expected error registering an alias used by another model")
+	}
+	if err := RegisterModel(ModelInfo{}); err == nil {
+		t.Error("expected error registering a model without an ID")
+	}
+}
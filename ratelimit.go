@@ -0,0 +1,57 @@
+package claude
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimit holds the values of the anthropic-ratelimit-* response headers.
+// Fields are nil when the corresponding header was not present or could
+// not be parsed, so a missing header is distinct from a remaining count of 0.
+// Bedrock and Vertex do not send these headers.
+// See https://docs.anthropic.com/en/api/rate-limits#response-headers for details.
+type RateLimit struct {
+	RequestsLimit     *int
+	RequestsRemaining *int
+	RequestsReset     *time.Time
+
+	TokensLimit     *int
+	TokensRemaining *int
+	TokensReset     *time.Time
+
+	InputTokensLimit     *int
+	InputTokensRemaining *int
+	InputTokensReset     *time.Time
+
+	OutputTokensLimit     *int
+	OutputTokensRemaining *int
+	OutputTokensReset     *time.Time
+}
+
+// RateLimitFromHeader parses the anthropic-ratelimit-* headers in h.
+func RateLimitFromHeader(h http.Header) RateLimit {
+	var rl RateLimit
+	parseRateLimitGroup(h, "requests", &rl.RequestsLimit, &rl.RequestsRemaining, &rl.RequestsReset)
+	parseRateLimitGroup(h, "tokens", &rl.TokensLimit, &rl.TokensRemaining, &rl.TokensReset)
+	parseRateLimitGroup(h, "input-tokens", &rl.InputTokensLimit, &rl.InputTokensRemaining, &rl.InputTokensReset)
+	parseRateLimitGroup(h, "output-tokens", &rl.OutputTokensLimit, &rl.OutputTokensRemaining, &rl.OutputTokensReset)
+	return rl
+}
+
+func parseRateLimitGroup(h http.Header, name string, limit, remaining **int, reset **time.Time) {
+	prefix := "anthropic-ratelimit-" + name + "-"
+	*limit = parseHeaderInt(h, prefix+"limit")
+	*remaining = parseHeaderInt(h, prefix+"remaining")
+	if t, err := time.Parse(time.RFC3339, h.Get(prefix+"reset")); err == nil {
+		*reset = &t
+	}
+}
+
+func parseHeaderInt(h http.Header, key string) *int {
+	n, err := strconv.Atoi(h.Get(key))
+	if err != nil {
+		return nil
+	}
+	return &n
+}
@@ -0,0 +1,366 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchema is the subset of JSON Schema used to describe tool inputs.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+}
+
+// SchemaFor derives a JSON schema from the type of v, which should be a
+// struct or a pointer to a struct.
+//
+// Struct fields are named by their json tag. Fields are required unless
+// they are pointers or have the omitempty option. The following
+// additional struct tags are supported:
+//
+//	description:"..."  sets the description of the property
+//	enum:"a,b,c"       restricts the property to the listed values
+func SchemaFor(v any) (*JSONSchema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, errors.New("schema: nil value")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema: %s is not a struct", t)
+	}
+	return schemaForType(t, make(map[reflect.Type]bool))
+}
+
+// ToolFor creates a custom Tool whose InputSchema is derived from the
+// type of input using SchemaFor.
+func ToolFor(name, description string, input any) (Tool, error) {
+	schema, err := SchemaFor(input)
+	if err != nil {
+		return Tool{}, err
+	}
+	return Tool{
+		Name:        name,
+		Description: description,
+		InputSchema: schema,
+	}, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func schemaForType(t reflect.Type, seen map[reflect.Type]bool) (*JSONSchema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &JSONSchema{Type: "string", Format: "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}, nil
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}, nil
+	case reflect.Interface:
+		return &JSONSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes []byte as a base64 string
+			return &JSONSchema{Type: "string"}, nil
+		}
+		items, err := schemaForType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("schema: unsupported map key type %s", t.Key())
+		}
+		values, err := schemaForType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if seen[t] {
+			return nil, fmt.Errorf("schema: recursive type %s is not supported", t)
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		s := &JSONSchema{
+			Type:       "object",
+			Properties: make(map[string]*JSONSchema),
+		}
+		if err := addStructFields(s, t, seen); err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("schema: unsupported type %s", t)
+	}
+}
+
+func addStructFields(s *JSONSchema, t reflect.Type, seen map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				// promote embedded struct fields like encoding/json does
+				if err := addStructFields(s, ft, seen); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := schemaForType(f.Type, seen)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		prop.Description = f.Tag.Get("description")
+
+		if enum := f.Tag.Get("enum"); enum != "" {
+			prop.Enum, err = parseEnum(enum, prop.Type)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+			}
+		}
+
+		s.Properties[name] = prop
+
+		optional := f.Type.Kind() == reflect.Pointer || hasOption(opts, "omitempty")
+		if !optional {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+func hasOption(opts, want string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == want {
+			return true
+		}
+	}
+	return false
+}
+
+func parseEnum(tag, typ string) ([]any, error) {
+	parts := strings.Split(tag, ",")
+	values := make([]any, len(parts))
+	for i, p := range parts {
+		switch typ {
+		case "string":
+			values[i] = p
+		case "integer":
+			n, err := strconv.ParseInt(p, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid integer enum value %q", p)
+			}
+			values[i] = n
+		case "number":
+			n, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number enum value %q", p)
+			}
+			values[i] = n
+		default:
+			return nil, fmt.Errorf("enum not supported for type %q", typ)
+		}
+	}
+	return values, nil
+}
+
+// ToolInputError is returned by DecodeInput when the model's tool input
+// does not match the expected schema.
+type ToolInputError struct {
+	ToolName string
+	Problems []string
+}
+
+func (e *ToolInputError) Error() string {
+	return fmt.Sprintf("invalid input for tool %q: %s", e.ToolName, strings.Join(e.Problems, "; "))
+}
+
+// Validate checks that v, a value decoded from JSON into an any,
+// conforms to the schema. It returns a list of problems found.
+func (s *JSONSchema) Validate(v any) []string {
+	var problems []string
+	s.validate(v, "input", &problems)
+	return problems
+}
+
+func (s *JSONSchema) validate(v any, path string, problems *[]string) {
+	addProblem := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if v == nil {
+		if s.Type != "" {
+			addProblem("expected %s, got null", s.Type)
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			addProblem("expected object, got %s", jsonTypeName(v))
+			return
+		}
+		for _, name := range s.Required {
+			if val, ok := obj[name]; !ok || val == nil {
+				*problems = append(*problems, path+"."+name+": required field missing")
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			prop := s.Properties[k]
+			if prop == nil {
+				prop = s.AdditionalProperties
+			}
+			if prop != nil && obj[k] != nil {
+				prop.validate(obj[k], path+"."+k, problems)
+			}
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			addProblem("expected array, got %s", jsonTypeName(v))
+			return
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			addProblem("expected string, got %s", jsonTypeName(v))
+			return
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			addProblem("expected boolean, got %s", jsonTypeName(v))
+			return
+		}
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != float64(int64(n)) {
+			addProblem("expected integer, got %s", jsonTypeName(v))
+			return
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			addProblem("expected number, got %s", jsonTypeName(v))
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		got, _ := json.Marshal(v)
+		for _, e := range s.Enum {
+			want, _ := json.Marshal(e)
+			if bytes.Equal(got, want) {
+				return
+			}
+		}
+		addProblem("value %s is not one of the allowed values", got)
+	}
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// DecodeInput validates the tool input against the schema derived from v
+// (see SchemaFor) and then unmarshals it into v, which must be a pointer
+// to a struct. Validation failures are returned as a *ToolInputError.
+func (t *TurnContentToolUse) DecodeInput(v any) error {
+	schema, err := SchemaFor(v)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(t.Input)
+	if err != nil {
+		return err
+	}
+
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return err
+	}
+
+	if problems := schema.Validate(generic); len(problems) > 0 {
+		return &ToolInputError{
+			ToolName: t.Name,
+			Problems: problems,
+		}
+	}
+
+	return json.Unmarshal(raw, v)
+}
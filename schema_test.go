@@ -0,0 +1,79 @@
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type weatherLocation struct {
+	City    string `json:"city" description:"City name"`
+	Country string `json:"country,omitempty"`
+}
+
+type weatherInput struct {
+	Location weatherLocation `json:"location"`
+	Unit     string          `json:"unit" enum:"celsius,fahrenheit"`
+	Days     *int            `json:"days" description:"Number of days to forecast"`
+	Tags     []string        `json:"tags,omitempty"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema, err := SchemaFor(&weatherInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `{"type":"object","properties":{"days":{"type":"integer","description":"Number of days to forecast"},"location":{"type":"object","properties":{"city":{"type":"string","description":"City name"},"country":{"type":"string"}},"required":["city"]},"tags":{"type":"array","items":{"type":"string"}},"unit":{"type":"string","enum":["celsius","fahrenheit"]}},"required":["location","unit"]}`
+
+	if diff := cmp.Diff(expect, string(got)); diff != "" {
+		t.Fatalf("SchemaFor() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeInput(t *testing.T) {
+	toolUse := TurnContentToolUse{
+		Typ:  TurnToolUse,
+		Name: "get_weather",
+		Input: map[string]any{
+			"location": map[string]any{"city": "Paris"},
+			"unit":     "celsius",
+			"days":     float64(3),
+		},
+	}
+
+	var input weatherInput
+	if err := toolUse.DecodeInput(&input); err != nil {
+		t.Fatal(err)
+	}
+	if input.Location.City != "Paris" || input.Unit != "celsius" || input.Days == nil || *input.Days != 3 {
+		t.Fatalf("unexpected decoded input: %+v", input)
+	}
+
+	toolUse.Input = map[string]any{
+		"location": map[string]any{"country": "FR"},
+		"unit":     "kelvin",
+		"days":     2.5,
+	}
+	err := toolUse.DecodeInput(&input)
+	var inputErr *ToolInputError
+	if !errors.As(err, &inputErr) {
+		t.Fatalf("expected ToolInputError, got %v", err)
+	}
+
+	expectProblems := []string{
+		"input.days: expected integer, got number",
+		"input.location.city: required field missing",
+		"input.unit: value \"kelvin\" is not one of the allowed values",
+	}
+	if diff := cmp.Diff(expectProblems, inputErr.Problems); diff != "" {
+		t.Fatalf("validation problems mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,307 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Server tool types. Server tools are executed by the API; their calls
+// and results are returned as server_tool_use and *_tool_result content
+// blocks, which must be kept in the conversation history.
+// See https://docs.anthropic.com/en/docs/agents-and-tools/tool-use/overview for details.
+const (
+	ToolTypeWebSearch20250305     = "web_search_20250305"
+	ToolTypeCodeExecution20250522 = "code_execution_20250522"
+)
+
+const (
+	ToolNameWebSearch     = "web_search"
+	ToolNameCodeExecution = "code_execution"
+)
+
+// UserLocation localizes web search results.
+type UserLocation struct {
+	// Type must be "approximate".
+	Type     string `json:"type"`
+	City     string `json:"city,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// WebSearchTool returns the web search server tool definition.
+// Set MaxUses, AllowedDomains or BlockedDomains and UserLocation on the
+// returned Tool to configure it.
+func WebSearchTool() Tool {
+	return Tool{
+		Type: ToolTypeWebSearch20250305,
+		Name: ToolNameWebSearch,
+	}
+}
+
+// CodeExecutionTool returns the code execution server tool definition.
+func CodeExecutionTool() Tool {
+	return Tool{
+		Type: ToolTypeCodeExecution20250522,
+		Name: ToolNameCodeExecution,
+	}
+}
+
+// ServerToolUsage counts server tool invocations for a message.
+type ServerToolUsage struct {
+	WebSearchRequests int `json:"web_search_requests"`
+}
+
+const (
+	TurnServerToolUse           = "server_tool_use"
+	TurnWebSearchToolResult     = "web_search_tool_result"
+	TurnCodeExecutionToolResult = "code_execution_tool_result"
+)
+
+const (
+	webSearchResultType            = "web_search_result"
+	webSearchToolResultErrorType   = "web_search_tool_result_error"
+	codeExecutionResultType        = "code_execution_result"
+	codeExecutionToolResultErrType = "code_execution_tool_result_error"
+)
+
+// TurnContentServerToolUse is a call to a server tool made by the model.
+type TurnContentServerToolUse struct {
+	Typ          string        `json:"type"`
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Input        any           `json:"input"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+func (t *TurnContentServerToolUse) Type() string {
+	return TurnServerToolUse
+}
+
+func (t *TurnContentServerToolUse) TextContent() string {
+	return ""
+}
+
+func (t *TurnContentServerToolUse) SetCacheControl(cc *CacheControl) {
+	t.CacheControl = cc
+}
+
+// ServerToolError is returned in place of a result when a server tool call fails.
+type ServerToolError struct {
+	// ErrorCode is e.g. "max_uses_exceeded", "too_many_requests",
+	// "invalid_tool_input" or "unavailable".
+	ErrorCode string `json:"error_code"`
+}
+
+func (e *ServerToolError) Error() string {
+	return fmt.Sprintf("server tool error: %s", e.ErrorCode)
+}
+
+// WebSearchResult is a single web search result.
+type WebSearchResult struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	// EncryptedContent must be passed back unmodified in multi-turn conversations.
+	EncryptedContent string `json:"encrypted_content"`
+	PageAge          string `json:"page_age,omitempty"`
+}
+
+// TurnContentWebSearchToolResult holds the results of a web search server tool call.
+// Exactly one of Results or Error is set.
+type TurnContentWebSearchToolResult struct {
+	Typ          string
+	ToolUseID    string
+	Results      []WebSearchResult
+	Error        *ServerToolError
+	CacheControl *CacheControl
+}
+
+func (t *TurnContentWebSearchToolResult) Type() string {
+	return TurnWebSearchToolResult
+}
+
+func (t *TurnContentWebSearchToolResult) TextContent() string {
+	return ""
+}
+
+func (t *TurnContentWebSearchToolResult) SetCacheControl(cc *CacheControl) {
+	t.CacheControl = cc
+}
+
+type webSearchResultJSON struct {
+	Type string `json:"type"`
+	WebSearchResult
+}
+
+type serverToolErrorJSON struct {
+	Type string `json:"type"`
+	ServerToolError
+}
+
+func (t *TurnContentWebSearchToolResult) MarshalJSON() ([]byte, error) {
+	var content any
+	if t.Error != nil {
+		content = serverToolErrorJSON{Type: webSearchToolResultErrorType, ServerToolError: *t.Error}
+	} else {
+		results := make([]webSearchResultJSON, len(t.Results))
+		for i, r := range t.Results {
+			results[i] = webSearchResultJSON{Type: webSearchResultType, WebSearchResult: r}
+		}
+		content = results
+	}
+
+	return json.Marshal(struct {
+		Type         string        `json:"type"`
+		ToolUseID    string        `json:"tool_use_id"`
+		Content      any           `json:"content"`
+		CacheControl *CacheControl `json:"cache_control,omitempty"`
+	}{
+		Type:         TurnWebSearchToolResult,
+		ToolUseID:    t.ToolUseID,
+		Content:      content,
+		CacheControl: t.CacheControl,
+	})
+}
+
+func (t *TurnContentWebSearchToolResult) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Type         string          `json:"type"`
+		ToolUseID    string          `json:"tool_use_id"`
+		Content      json.RawMessage `json:"content"`
+		CacheControl *CacheControl   `json:"cache_control"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*t = TurnContentWebSearchToolResult{
+		Typ:          raw.Type,
+		ToolUseID:    raw.ToolUseID,
+		CacheControl: raw.CacheControl,
+	}
+
+	// content is either a list of results or a single error object
+	if len(raw.Content) > 0 && raw.Content[0] == '[' {
+		var results []webSearchResultJSON
+		if err := json.Unmarshal(raw.Content, &results); err != nil {
+			return err
+		}
+		t.Results = make([]WebSearchResult, len(results))
+		for i, r := range results {
+			t.Results[i] = r.WebSearchResult
+		}
+		return nil
+	}
+
+	var errContent serverToolErrorJSON
+	if err := json.Unmarshal(raw.Content, &errContent); err != nil {
+		return err
+	}
+	if errContent.Type != webSearchToolResultErrorType {
+		return fmt.Errorf("unknown web search result content type: %s", errContent.Type)
+	}
+	t.Error = &errContent.ServerToolError
+	return nil
+}
+
+// CodeExecutionResult is the output of a code execution server tool call.
+type CodeExecutionResult struct {
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	ReturnCode int    `json:"return_code"`
+	// Content lists files created by the code. Download them with the Files API.
+	Content []CodeExecutionOutput `json:"content"`
+}
+
+// CodeExecutionOutput is a file created during code execution.
+type CodeExecutionOutput struct {
+	Type   string `json:"type"`
+	FileID string `json:"file_id"`
+}
+
+// TurnContentCodeExecutionToolResult holds the result of a code execution server tool call.
+// Exactly one of Result or Error is set.
+type TurnContentCodeExecutionToolResult struct {
+	Typ          string
+	ToolUseID    string
+	Result       *CodeExecutionResult
+	Error        *ServerToolError
+	CacheControl *CacheControl
+}
+
+func (t *TurnContentCodeExecutionToolResult) Type() string {
+	return TurnCodeExecutionToolResult
+}
+
+func (t *TurnContentCodeExecutionToolResult) TextContent() string {
+	return ""
+}
+
+func (t *TurnContentCodeExecutionToolResult) SetCacheControl(cc *CacheControl) {
+	t.CacheControl = cc
+}
+
+func (t *TurnContentCodeExecutionToolResult) MarshalJSON() ([]byte, error) {
+	var content any
+	if t.Error != nil {
+		content = serverToolErrorJSON{Type: codeExecutionToolResultErrType, ServerToolError: *t.Error}
+	} else if t.Result != nil {
+		result := *t.Result
+		if result.Content == nil {
+			result.Content = []CodeExecutionOutput{}
+		}
+		content = struct {
+			Type string `json:"type"`
+			CodeExecutionResult
+		}{
+			Type:                codeExecutionResultType,
+			CodeExecutionResult: result,
+		}
+	}
+
+	return json.Marshal(struct {
+		Type         string        `json:"type"`
+		ToolUseID    string        `json:"tool_use_id"`
+		Content      any           `json:"content"`
+		CacheControl *CacheControl `json:"cache_control,omitempty"`
+	}{
+		Type:         TurnCodeExecutionToolResult,
+		ToolUseID:    t.ToolUseID,
+		Content:      content,
+		CacheControl: t.CacheControl,
+	})
+}
+
+func (t *TurnContentCodeExecutionToolResult) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Type      string `json:"type"`
+		ToolUseID string `json:"tool_use_id"`
+		Content   struct {
+			Type string `json:"type"`
+			ServerToolError
+			CodeExecutionResult
+		} `json:"content"`
+		CacheControl *CacheControl `json:"cache_control"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*t = TurnContentCodeExecutionToolResult{
+		Typ:          raw.Type,
+		ToolUseID:    raw.ToolUseID,
+		CacheControl: raw.CacheControl,
+	}
+
+	switch raw.Content.Type {
+	case codeExecutionResultType:
+		result := raw.Content.CodeExecutionResult
+		t.Result = &result
+	case codeExecutionToolResultErrType:
+		e := raw.Content.ServerToolError
+		t.Error = &e
+	default:
+		return fmt.Errorf("unknown code execution result content type: %s", raw.Content.Type)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package claude
+
+import "encoding/json"
+
+// ContentSource is the source of the data for an image or document content block.
+// It is one of *Base64Source, *URLSource, *FileSource, *TextSource, *ContentBlockSource
+// or *UnknownSource.
+// Images support base64, url and file sources.
+type ContentSource interface {
+	SourceType() string
+}
+
+const (
+	SourceBase64  = "base64"
+	SourceURL     = "url"
+	SourceFile    = "file"
+	SourceText    = "text"
+	SourceContent = "content"
+)
+
+// Base64Source is inline binary data. It is base64 encoded when marshaled.
+type Base64Source struct {
+	MediaType string
+	Data      []byte
+}
+
+func (s *Base64Source) SourceType() string {
+	return SourceBase64
+}
+
+func (s *Base64Source) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type      string `json:"type"`
+		MediaType string `json:"media_type"`
+		Data      []byte `json:"data"`
+	}{
+		Type:      SourceBase64,
+		MediaType: s.MediaType,
+		Data:      s.Data,
+	})
+}
+
+// URLSource references an image or PDF by URL. The API fetches it when
+// processing the request.
+type URLSource struct {
+	URL string
+}
+
+func (s *URLSource) SourceType() string {
+	return SourceURL
+}
+
+func (s *URLSource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	}{
+		Type: SourceURL,
+		URL:  s.URL,
+	})
+}
+
+// FilesBeta is the beta flag required to reference uploaded files with FileSource.
+// The anthropic client adds it automatically; see MessageRequest.RequiredBetas.
+const FilesBeta = "files-api-2025-04-14"
+
+// FileSource references a file previously uploaded with the Files API.
+// Requests using it must enable FilesBeta.
+type FileSource struct {
+	FileID string
+}
+
+func (s *FileSource) SourceType() string {
+	return SourceFile
+}
+
+func (s *FileSource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type   string `json:"type"`
+		FileID string `json:"file_id"`
+	}{
+		Type:   SourceFile,
+		FileID: s.FileID,
+	})
+}
+
+// TextSource is inline plain text.
+type TextSource struct {
+	MediaType string
+	Data      string
+}
+
+func (s *TextSource) SourceType() string {
+	return SourceText
+}
+
+func (s *TextSource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type      string `json:"type"`
+		MediaType string `json:"media_type"`
+		Data      string `json:"data"`
+	}{
+		Type:      SourceText,
+		MediaType: s.MediaType,
+		Data:      s.Data,
+	})
+}
+
+// ContentBlockSource is a list of content blocks that make up a custom document.
+type ContentBlockSource struct {
+	Content []TurnContent
+}
+
+func (s *ContentBlockSource) SourceType() string {
+	return SourceContent
+}
+
+func (s *ContentBlockSource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type    string        `json:"type"`
+		Content []TurnContent `json:"content"`
+	}{
+		Type:    SourceContent,
+		Content: s.Content,
+	})
+}
+
+// UnknownSource holds a source of a type not known to this library.
+// It marshals back to the original JSON so it can be passed back
+// unmodified in multi-turn conversations.
+type UnknownSource struct {
+	Typ string
+	Raw json.RawMessage
+}
+
+func (s *UnknownSource) SourceType() string {
+	return s.Typ
+}
+
+func (s *UnknownSource) MarshalJSON() ([]byte, error) {
+	return s.Raw, nil
+}
+
+func unmarshalContentSource(raw json.RawMessage) (ContentSource, error) {
+	var source struct {
+		Type      string            `json:"type"`
+		MediaType string            `json:"media_type"`
+		Data      json.RawMessage   `json:"data"`
+		URL       string            `json:"url"`
+		FileID    string            `json:"file_id"`
+		Content   []json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(raw, &source); err != nil {
+		return nil, err
+	}
+
+	switch source.Type {
+	case SourceBase64:
+		s := Base64Source{MediaType: source.MediaType}
+		if err := json.Unmarshal(source.Data, &s.Data); err != nil {
+			return nil, err
+		}
+		return &s, nil
+	case SourceURL:
+		return &URLSource{URL: source.URL}, nil
+	case SourceFile:
+		return &FileSource{FileID: source.FileID}, nil
+	case SourceText:
+		s := TextSource{MediaType: source.MediaType}
+		if err := json.Unmarshal(source.Data, &s.Data); err != nil {
+			return nil, err
+		}
+		return &s, nil
+	case SourceContent:
+		content, err := unmarshalTurnContents(source.Content)
+		if err != nil {
+			return nil, err
+		}
+		return &ContentBlockSource{Content: content}, nil
+	default:
+		// keep sources from newer API versions so they survive a round trip
+		return &UnknownSource{
+			Typ: source.Type,
+			Raw: append(json.RawMessage(nil), raw...),
+		}, nil
+	}
+}
+
+// usesFileSource reports whether c or any content nested in it references an uploaded file.
+func usesFileSource(c TurnContent) bool {
+	var src ContentSource
+	switch b := c.(type) {
+	case *turnContentImage:
+		src = b.Source
+	case *TurnContentDocument:
+		src = b.Source
+	case *turnContentToolResult:
+		for _, block := range b.Blocks {
+			if usesFileSource(block) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+
+	switch s := src.(type) {
+	case *FileSource:
+		return true
+	case *ContentBlockSource:
+		for _, block := range s.Content {
+			if usesFileSource(block) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package claude
+
+import "slices"
+
+// Anthropic-defined tool types. These tools have a fixed schema that is
+// built into the model, so they are defined by Type and Name instead of
+// an InputSchema. Your code is still responsible for executing them.
+// See https://docs.anthropic.com/en/docs/agents-and-tools/tool-use/overview for details.
+const (
+	ToolTypeBash20241022 = "bash_20241022"
+	ToolTypeBash20250124 = "bash_20250124"
+
+	ToolTypeTextEditor20241022 = "text_editor_20241022"
+	ToolTypeTextEditor20250124 = "text_editor_20250124"
+	// For Claude 4 models. Does not support the undo_edit command.
+	ToolTypeTextEditor20250429 = "text_editor_20250429"
+	// For Claude 4 models. Supports Tool.MaxCharacters.
+	ToolTypeTextEditor20250728 = "text_editor_20250728"
+
+	ToolTypeComputer20241022 = "computer_20241022"
+	ToolTypeComputer20250124 = "computer_20250124"
+)
+
+// Names the model uses for the Anthropic-defined tools.
+const (
+	ToolNameBash             = "bash"
+	ToolNameComputer         = "computer"
+	ToolNameStrReplaceEditor = "str_replace_editor"
+	// The text editor name for ToolTypeTextEditor20250429 and later.
+	ToolNameStrReplaceBasedEditTool = "str_replace_based_edit_tool"
+)
+
+// toolBetas are the beta flags required to use each Anthropic-defined tool version.
+var toolBetas = map[string]string{
+	ToolTypeBash20241022:       "computer-use-2024-10-22",
+	ToolTypeTextEditor20241022: "computer-use-2024-10-22",
+	ToolTypeComputer20241022:   "computer-use-2024-10-22",
+	ToolTypeBash20250124:       "computer-use-2025-01-24",
+	ToolTypeTextEditor20250124: "computer-use-2025-01-24",
+	ToolTypeComputer20250124:   "computer-use-2025-01-24",
+
+	ToolTypeCodeExecution20250522: "code-execution-2025-05-22",
+}
+
+// RequiredBeta returns the beta flag needed to use the tool, or "" if none is needed.
+func (t *Tool) RequiredBeta() string {
+	return toolBetas[t.Type]
+}
+
+// ToolBetas returns the beta flags required by the tools in the request,
+// excluding any already listed in req.AnthropicBeta.
+func (r *MessageRequest) ToolBetas() []string {
+	seen := make(map[string]bool)
+	for _, beta := range r.AnthropicBeta {
+		seen[beta] = true
+	}
+
+	var betas []string
+	for i := range r.Tools {
+		beta := r.Tools[i].RequiredBeta()
+		if beta != "" && !seen[beta] {
+			seen[beta] = true
+			betas = append(betas, beta)
+		}
+	}
+	return betas
+}
+
+// RequiredBetas returns the beta flags required by the tools and content in
+// the request, excluding any already listed in req.AnthropicBeta. In addition
+// to ToolBetas it includes FilesBeta if any content uses a FileSource.
+func (r *MessageRequest) RequiredBetas() []string {
+	betas := r.ToolBetas()
+	if slices.Contains(r.AnthropicBeta, FilesBeta) {
+		return betas
+	}
+	for _, m := range r.Messages {
+		for _, c := range m.Content {
+			if usesFileSource(c) {
+				return append(betas, FilesBeta)
+			}
+		}
+	}
+	return betas
+}
+
+// BashTool returns the bash tool definition for the given version, e.g. ToolTypeBash20250124.
+// Decode its tool_use input with BashInput.
+func BashTool(toolType string) Tool {
+	return Tool{
+		Type: toolType,
+		Name: ToolNameBash,
+	}
+}
+
+// TextEditorTool returns the text editor tool definition for the given version,
+// e.g. ToolTypeTextEditor20250728. Decode its tool_use input with TextEditorInput.
+func TextEditorTool(toolType string) Tool {
+	name := ToolNameStrReplaceBasedEditTool
+	if toolType == ToolTypeTextEditor20241022 || toolType == ToolTypeTextEditor20250124 {
+		name = ToolNameStrReplaceEditor
+	}
+	return Tool{
+		Type: toolType,
+		Name: name,
+	}
+}
+
+// ComputerTool returns the computer use tool definition for the given version,
+// e.g. ToolTypeComputer20250124, and display size in pixels.
+// Decode its tool_use input with ComputerInput.
+func ComputerTool(toolType string, displayWidthPx, displayHeightPx int) Tool {
+	return Tool{
+		Type:            toolType,
+		Name:            ToolNameComputer,
+		DisplayWidthPx:  displayWidthPx,
+		DisplayHeightPx: displayHeightPx,
+	}
+}
+
+// BashInput is the tool_use input for the bash tool.
+type BashInput struct {
+	// The command to run. Empty when Restart is set.
+	Command string `json:"command,omitempty"`
+	// Restart the bash session.
+	Restart bool `json:"restart,omitempty"`
+}
+
+// Commands used by the text editor tool.
+const (
+	TextEditorView       = "view"
+	TextEditorCreate     = "create"
+	TextEditorStrReplace = "str_replace"
+	TextEditorInsert     = "insert"
+	TextEditorUndoEdit   = "undo_edit"
+)
+
+// TextEditorInput is the tool_use input for the text editor tool.
+type TextEditorInput struct {
+	Command string `json:"command" enum:"view,create,str_replace,insert,undo_edit"`
+	// Path of the file or directory to operate on.
+	Path string `json:"path"`
+	// Lines to view, 1-indexed. An end of -1 means the end of the file. Used by view.
+	ViewRange []int `json:"view_range,omitempty"`
+	// Contents of the new file. Used by create.
+	FileText string `json:"file_text,omitempty"`
+	// Text to replace. Used by str_replace.
+	OldStr string `json:"old_str,omitempty"`
+	// Replacement text for str_replace, or the text to insert for insert.
+	NewStr string `json:"new_str,omitempty"`
+	// Line after which to insert NewStr, 0 for the start of the file. Used by insert.
+	InsertLine *int `json:"insert_line,omitempty"`
+}
+
+// ComputerInput is the tool_use input for the computer use tool.
+type ComputerInput struct {
+	// The action to perform, such as "screenshot", "left_click", "type", "key" or "scroll".
+	Action string `json:"action"`
+	// [x, y] pixel coordinate for mouse actions.
+	Coordinate []int `json:"coordinate,omitempty"`
+	// [x, y] start coordinate for left_click_drag.
+	StartCoordinate []int `json:"start_coordinate,omitempty"`
+	// Text to type, or the key combination for key and hold_key.
+	Text string `json:"text,omitempty"`
+	// One of "up", "down", "left" or "right". Used by scroll.
+	ScrollDirection string `json:"scroll_direction,omitempty"`
+	// Number of scroll wheel clicks. Used by scroll.
+	ScrollAmount int `json:"scroll_amount,omitempty"`
+	// Seconds to wait or hold a key. Used by wait and hold_key.
+	Duration float64 `json:"duration,omitempty"`
+	// Modifier keys to hold during a click or scroll.
+	Key string `json:"key,omitempty"`
+}
@@ -9,6 +9,12 @@ import (
 type VertexModel string
 
 const (
+	ClaudeOpus4Dot5     VertexModel = "claude-opus-4-5@20251101"
+	ClaudeHaiku4Dot5    VertexModel = "claude-haiku-4-5@20251001"
+	ClaudeSonnet4Dot5   VertexModel = "claude-sonnet-4-5@20250929"
+	ClaudeOpus4Dot1     VertexModel = "claude-opus-4-1@20250805"
+	ClaudeOpus4         VertexModel = "claude-opus-4@20250514"
+	ClaudeSonnet4       VertexModel = "claude-sonnet-4@20250514"
 	Claude3Dot7Sonnet   VertexModel = "claude-3-7-sonnet@20250219"
 	Claude3Dot5SonnetV2 VertexModel = "claude-3-5-sonnet-v2@20241022"
 	Claude3Dot5Haiku    VertexModel = "claude-3-5-haiku@20241022"
@@ -18,54 +24,51 @@ const (
 	Claude3Haiku        VertexModel = "claude-3-haiku@20240307"
 )
 
-var Models = []VertexModel{
-	Claude3Dot7Sonnet,
-	Claude3Dot5SonnetV2,
-	Claude3Dot5Haiku,
-	Claude3Dot5Sonnet,
-	Claude3Opus,
-	Claude3Sonnet,
-	Claude3Haiku,
+// Models is the list of models built into the claude model registry that are available on Vertex.
+// Use claude.FindModels to include models added with claude.RegisterModel.
+var Models = registeredModels()
+
+func registeredModels() []VertexModel {
+	var models []VertexModel
+	for _, id := range claude.Models() {
+		if m, _ := claude.LookupModel(id); m.VertexID != "" {
+			models = append(models, VertexModel(m.VertexID))
+		}
+	}
+	return models
+}
+
+// legacyPrettyNames keeps the names PrettyName returned for these models
+// before it used the model registry.
+var legacyPrettyNames = map[VertexModel]string{
+	Claude3Dot7Sonnet:   "Claude 3.7 Sonnet",
+	Claude3Dot5SonnetV2: "Claude 3.5 Sonnet V2",
+	Claude3Dot5Sonnet:   "Claude 3.5 Sonnet",
+	Claude3Dot5Haiku:    "Claude 3.5 Haiku",
+	Claude3Opus:         "Claude 3 Opus",
+	Claude3Sonnet:       "Claude 3 Sonnet",
+	Claude3Haiku:        "Claude 3 Haiku",
 }
 
 func (m VertexModel) PrettyName() string {
-	switch m {
-	case Claude3Dot7Sonnet:
-		return "Claude 3.7 Sonnet"
-	case Claude3Dot5SonnetV2:
-		return "Claude 3.5 Sonnet V2"
-	case Claude3Dot5Sonnet:
-		return "Claude 3.5 Sonnet"
-	case Claude3Dot5Haiku:
-		return "Claude 3.5 Haiku"
-	case Claude3Opus:
-		return "Claude 3 Opus"
-	case Claude3Sonnet:
-		return "Claude 3 Sonnet"
-	case Claude3Haiku:
-		return "Claude 3 Haiku"
-	default:
-		return fmt.Sprintf("Unknown VertexModel<%s>", m)
+	if name, ok := legacyPrettyNames[m]; ok {
+		return name
 	}
+	if info, ok := claude.LookupModel(string(m)); ok && info.VertexID == string(m) {
+		return info.DisplayName
+	}
+	return fmt.Sprintf("Unknown VertexModel<%s>", m)
 }
 
+// ModelToVertexModel maps a model ID, alias or Vertex model ID to its
+// Vertex model ID using the claude model registry.
 func ModelToVertexModel(m string) (VertexModel, error) {
-	switch m {
-	case claude.Claude3Dot7SonnetLatest, claude.Claude3Dot7Sonnet2502, string(Claude3Dot7Sonnet):
-		return Claude3Dot7Sonnet, nil
-	case claude.Claude3Dot5Sonnet2410, string(Claude3Dot5SonnetV2):
-		return Claude3Dot5SonnetV2, nil
-	case claude.Claude3Dot5Haiku, string(Claude3Dot5Haiku):
-		return Claude3Dot5Haiku, nil
-	case claude.Claude3Dot5Sonnet, string(Claude3Dot5Sonnet):
-		return Claude3Dot5Sonnet, nil
-	case claude.Claude3Opus, string(Claude3Opus):
-		return Claude3Opus, nil
-	case claude.Claude3Sonnet, string(Claude3Sonnet):
-		return Claude3Sonnet, nil
-	case claude.Claude3Haiku, string(Claude3Haiku):
-		return Claude3Haiku, nil
+	info, ok := claude.LookupModel(m)
+	if !ok {
+		return VertexModel(m), fmt.Errorf("Unknown model: %s", m)
 	}
-
-	return VertexModel(m), fmt.Errorf("Unknown model: %s", m)
+	if info.VertexID == "" {
+		return VertexModel(m), fmt.Errorf("Model not available on vertex: %s", m)
+	}
+	return VertexModel(info.VertexID), nil
 }
@@ -0,0 +1,51 @@
+package vertex
+
+import (
+	"testing"
+
+	"github.com/psanford/claude"
+)
+
+func TestModelToVertexModel(t *testing.T) {
+	tests := []struct {
+		model   string
+		want    VertexModel
+		wantErr bool
+	}{
+		{model: claude.Claude3Dot5HaikuLatest, want: Claude3Dot5Haiku},
+		{model: claude.Claude3Dot5SonnetLatest, want: Claude3Dot5SonnetV2},
+		{model: claude.ClaudeHaiku4Dot5, want: ClaudeHaiku4Dot5},
+		{model: string(Claude3Dot7Sonnet), want: Claude3Dot7Sonnet},
+		// not available on vertex
+		{model: claude.Claude2Dot1, wantErr: true},
+		{model: "claude-hiaku-4-5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ModelToVertexModel(tt.model)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ModelToVertexModel(%q) error = %v, wantErr %v", tt.model, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ModelToVertexModel(%q) = %s, want %s", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestPrettyName(t *testing.T) {
+	tests := []struct {
+		model VertexModel
+		want  string
+	}{
+		{Claude3Dot7Sonnet, "Claude 3.7 Sonnet"},
+		{Claude3Dot5SonnetV2, "Claude 3.5 Sonnet V2"},
+		{ClaudeSonnet4Dot5, "Claude Sonnet 4.5"},
+		{"claude-unknown@20250101", "Unknown VertexModel<claude-unknown@20250101>"},
+	}
+	for _, tt := range tests {
+		if got := tt.model.PrettyName(); got != tt.want {
+			t.Errorf("%s.PrettyName() = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
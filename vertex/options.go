@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/psanford/claude/clientiface"
 	"golang.org/x/oauth2/google"
 )
 
@@ -85,3 +86,19 @@ func WithCredentials(creds *google.Credentials) Option {
 		credentials: creds,
 	}
 }
+
+type retryPolicyOption struct {
+	p *clientiface.RetryPolicy
+}
+
+func (o *retryPolicyOption) set(c *Client) {
+	c.retryPolicy = o.p
+}
+
+// WithRetryPolicy enables automatic retries of rate limit and overloaded
+// errors. See clientiface.DefaultRetryPolicy for a reasonable default.
+func WithRetryPolicy(p *clientiface.RetryPolicy) Option {
+	return &retryPolicyOption{
+		p: p,
+	}
+}
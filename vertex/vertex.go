@@ -8,11 +8,13 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/psanford/claude"
 	"github.com/psanford/claude/clientiface"
 	"github.com/psanford/claude/internal/request"
 	"github.com/psanford/claude/internal/responseparser"
+	"github.com/psanford/claude/internal/retry"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -23,6 +25,7 @@ type Client struct {
 	credentials  *google.Credentials
 	roundTripper http.RoundTripper
 	debugLogger  *slog.Logger
+	retryPolicy  *clientiface.RetryPolicy
 }
 
 var clientIfaceAssert = clientiface.Client(&Client{})
@@ -38,6 +41,11 @@ func NewClient(opts ...Option) *Client {
 }
 
 func (c *Client) Message(ctx context.Context, req *claude.MessageRequest, options ...clientiface.Option) (claude.MessageResponse, error) {
+	ro := clientiface.NewRequestOptions(options...)
+
+	// work on a copy so the caller can reuse req
+	reqCopy := *req
+	req = &reqCopy
 	request.SetDefaults(req)
 
 	if req.AnthropicVersion == "" {
@@ -58,6 +66,10 @@ func (c *Client) Message(ctx context.Context, req *claude.MessageRequest, option
 	}
 	req.Model = ""
 
+	// anthropic_beta is only valid in the request body for bedrock
+	betas := append(req.ToolBetas(), req.AnthropicBeta...)
+	req.AnthropicBeta = nil
+
 	jsonReq, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -70,26 +82,48 @@ func (c *Client) Message(ctx context.Context, req *claude.MessageRequest, option
 
 	messageURL := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/anthropic/models/%s:%s", c.region, c.projectID, c.region, vertexModel, apiMethod)
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", messageURL, bytes.NewReader(jsonReq))
-	if err != nil {
-		return nil, err
-	}
-
 	headers := make(http.Header)
 	headers.Add("content-type", "application/json")
-	httpReq.Header = headers
+	for _, beta := range betas {
+		headers.Add("anthropic-beta", beta)
+	}
+	request.ApplyHeaders(headers, ro)
 
 	client, err := c.httpClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		return nil, err
+	debugLogger := c.debugLogger
+	if ro.DebugLogger != nil {
+		debugLogger = ro.DebugLogger
 	}
 
-	return responseparser.HandleResponse(ctx, resp, c.debugLogger)
+	retryPolicy := c.retryPolicy
+	if ro.RetryPolicy != nil {
+		retryPolicy = ro.RetryPolicy
+	}
+
+	return retry.Do(ctx, ro.Timeout, retryPolicy, responseparser.ClassifyError, func(ctx context.Context) (claude.MessageResponse, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", messageURL, bytes.NewReader(jsonReq))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header = headers.Clone()
+
+		start := time.Now()
+		resp, err := client.Do(httpReq)
+		if err != nil {
+			return nil, err
+		}
+		elapsed := time.Since(start)
+
+		if ro.ResponseHook != nil {
+			ro.ResponseHook(resp)
+		}
+
+		return responseparser.HandleResponse(ctx, resp, elapsed, debugLogger)
+	})
 }
 
 func (c *Client) httpClient(ctx context.Context) (*http.Client, error) {
@@ -0,0 +1,84 @@
+package vertex
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/psanford/claude"
+	"github.com/psanford/claude/clientiface"
+)
+
+// redirectTransport sends every request to a local test server.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(WithRegion("us-east5"), WithProjectID("test-project"), WithRoundTripper(&redirectTransport{target: u}))
+}
+
+func TestMessageReuseRequest(t *testing.T) {
+	var gotPaths []string
+	var gotBetas [][]string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path)
+		gotBetas = append(gotBetas, r.Header.Values("anthropic-beta"))
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, `{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Hello!"}],"stop_reason":"end_turn","stop_sequence":null,"usage":{"input_tokens":10,"output_tokens":3}}`)
+	}))
+
+	req := &claude.MessageRequest{
+		Model:         claude.Claude3Haiku,
+		AnthropicBeta: []string{"token-efficient-tools-2025-02-19"},
+		Messages: []claude.MessageTurn{
+			{Role: claude.RoleUser, Content: []claude.TurnContent{claude.TextContent("hi")}},
+		},
+	}
+
+	for i, opts := range [][]clientiface.Option{
+		{clientiface.WithBeta("some-beta-2025-01-01")},
+		nil,
+	} {
+		resp, err := client.Message(context.Background(), req, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := claude.Accumulate(resp); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Contains(gotBetas[i], "token-efficient-tools-2025-02-19") {
+			t.Errorf("call %d: anthropic-beta headers = %v, missing request beta", i, gotBetas[i])
+		}
+		if hasOpt := slices.Contains(gotBetas[i], "some-beta-2025-01-01"); hasOpt != (len(opts) > 0) {
+			t.Errorf("call %d: anthropic-beta headers = %v", i, gotBetas[i])
+		}
+	}
+
+	if gotPaths[0] != gotPaths[1] {
+		t.Errorf("request paths differ: %v", gotPaths)
+	}
+	if req.Model != claude.Claude3Haiku || !slices.Equal(req.AnthropicBeta, []string{"token-efficient-tools-2025-02-19"}) {
+		t.Errorf("request was modified: model=%q betas=%v", req.Model, req.AnthropicBeta)
+	}
+}